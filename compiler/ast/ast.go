@@ -3,43 +3,14 @@ package ast
 
 import (
 	"bytes"
-	"log"
-	"os"
-	"sync"
 	"taquion/compiler/token"
 )
 
-var (
-	logger   *log.Logger
-	logFile  *os.File
-	initOnce sync.Once
-)
-
-func initLogger() {
-	initOnce.Do(func() {
-		if err := os.MkdirAll("log", 0755); err != nil {
-			log.Fatalf("Erro ao criar diretório de log: %v", err)
-		}
-		var err error
-		logFile, err = os.OpenFile("log/ast.log", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
-		if err != nil {
-			log.Fatalf("Erro ao abrir o arquivo de log ast.log: %v", err)
-		}
-		logger = log.New(logFile, "AST: ", log.LstdFlags)
-		logger.Println("=== Nova sessão de log da AST iniciada ===")
-	})
-}
-
-func CloseLogger() {
-	if logFile != nil {
-		logger.Println("=== Encerrando sessão de log da AST ===")
-		logFile.Close()
-	}
-}
-
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position // posição do primeiro caractere do nó
+	End() token.Position // posição imediatamente após o último caractere do nó
 }
 
 type Statement interface {
@@ -56,10 +27,18 @@ type Program struct {
 	Statements []Statement
 }
 
+// Package reúne os arquivos de um pacote, que são compilados juntos num único módulo.
+type Package struct {
+	Path  string // caminho de importação relativo à raiz do módulo ("" para o pacote principal)
+	Name  string // nome declarado em 'package'
+	Files []*Program
+}
+
 type CompositeLiteral struct {
 	Token    token.Token // o token de abertura '{'
 	TypeName *Identifier // Pessoa
 	Fields   []*KeyValueExpr
+	RBrace   token.Token // o token de fechamento '}'
 }
 
 func (cl *CompositeLiteral) String() string {
@@ -81,6 +60,13 @@ func (cl *CompositeLiteral) String() string {
 
 func (cl *CompositeLiteral) expressionNode()      {}
 func (cl *CompositeLiteral) TokenLiteral() string { return cl.Token.Literal }
+func (cl *CompositeLiteral) Pos() token.Position {
+	if cl.TypeName != nil {
+		return cl.TypeName.Pos()
+	}
+	return cl.Token.Pos
+}
+func (cl *CompositeLiteral) End() token.Position { return cl.RBrace.End }
 
 type KeyValueExpr struct {
 	Key   *Identifier
@@ -91,6 +77,9 @@ func (kv *KeyValueExpr) String() string {
 	return kv.Key.String() + ": " + kv.Value.String()
 }
 
+func (kv *KeyValueExpr) Pos() token.Position { return kv.Key.Pos() }
+func (kv *KeyValueExpr) End() token.Position { return endOf(kv.Value, kv.Key.End()) }
+
 func (p *Program) TokenLiteral() string {
 	if len(p.Statements) > 0 {
 		return p.Statements[0].TokenLiteral()
@@ -98,9 +87,21 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
+func (p *Program) End() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+	return token.Position{}
+}
+
 func (p *Program) String() string {
-	initLogger()
-	logger.Println("Gerando string para o nó Program")
 	var out bytes.Buffer
 	for _, s := range p.Statements {
 		out.WriteString(s.String())
@@ -111,11 +112,18 @@ func (p *Program) String() string {
 type Identifier struct {
 	Token token.Token
 	Value string
-	Type  *Identifier
+	Type  Expression // tipo anotado de um parâmetro: um nome, um ArrayType ou um FuncType
 }
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() token.Position  { return i.Token.Pos }
+func (i *Identifier) End() token.Position {
+	if i.Type != nil && i.Type.End().IsValid() {
+		return i.Type.End()
+	}
+	return i.Token.End
+}
 
 func (i *Identifier) String() string {
 	if i.Type != nil {
@@ -138,13 +146,19 @@ func (i *Identifier) TypeString() string {
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() token.Position  { return il.Token.Pos }
+func (il *IntegerLiteral) End() token.Position  { return il.Token.End }
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() token.Position  { return sl.Token.Pos }
+func (sl *StringLiteral) End() token.Position  { return sl.Token.End }
 
 func (ie *InfixExpression) expressionNode()      {}
 func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *InfixExpression) Pos() token.Position  { return posOf(ie.Left, ie.Token.Pos) }
+func (ie *InfixExpression) End() token.Position  { return endOf(ie.Right, ie.Token.End) }
 func (ie *InfixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -154,3 +168,19 @@ func (ie *InfixExpression) String() string {
 	out.WriteString(")")
 	return out.String()
 }
+
+// posOf retorna a posição inicial de um nó, ou fallback quando o nó está ausente.
+func posOf(n Node, fallback token.Position) token.Position {
+	if n == nil {
+		return fallback
+	}
+	return n.Pos()
+}
+
+// endOf retorna a posição final de um nó, ou fallback quando o nó está ausente.
+func endOf(n Node, fallback token.Position) token.Position {
+	if n == nil {
+		return fallback
+	}
+	return n.End()
+}
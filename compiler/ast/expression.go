@@ -3,22 +3,58 @@ package ast
 
 import (
 	"bytes"
+	"strconv"
 	"strings"
 	"taquion/compiler/token"
 )
 
 // --- Nós de Expressão ---
 
+// BadExpr marca uma expressão que não pôde ser analisada.
+type BadExpr struct {
+	From token.Token // primeiro token da expressão
+	To   token.Token // último token consumido
+}
+
+func (be *BadExpr) expressionNode()      {}
+func (be *BadExpr) TokenLiteral() string { return be.From.Literal }
+func (be *BadExpr) Pos() token.Position  { return be.From.Pos }
+func (be *BadExpr) End() token.Position  { return be.To.End }
+func (be *BadExpr) String() string       { return "<expressão inválida>" }
+
 type IntegerLiteral struct {
 	Token token.Token
-	Value int64
+	Value uint64 // magnitude do literal; um '-' na frente é um PrefixExpression
 }
 
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() token.Position  { return fl.Token.Pos }
+func (fl *FloatLiteral) End() token.Position  { return fl.Token.End }
+
 type StringLiteral struct {
 	Token token.Token
 	Value string
 }
 
+// CharLiteral representa um literal de caractere, como 'a' ou '\n', com seu code point.
+type CharLiteral struct {
+	Token token.Token
+	Value rune
+}
+
+func (cl *CharLiteral) expressionNode()      {}
+func (cl *CharLiteral) TokenLiteral() string { return cl.Token.Literal }
+func (cl *CharLiteral) String() string       { return strconv.QuoteRune(cl.Value) }
+func (cl *CharLiteral) Pos() token.Position  { return cl.Token.Pos }
+func (cl *CharLiteral) End() token.Position  { return cl.Token.End }
+
 type BooleanLiteral struct {
 	Token token.Token
 	Value bool
@@ -27,6 +63,34 @@ type BooleanLiteral struct {
 func (b *BooleanLiteral) expressionNode()      {}
 func (b *BooleanLiteral) TokenLiteral() string { return b.Token.Literal }
 func (b *BooleanLiteral) String() string       { return b.Token.Literal }
+func (b *BooleanLiteral) Pos() token.Position  { return b.Token.Pos }
+func (b *BooleanLiteral) End() token.Position  { return b.Token.End }
+
+// InterpolatedString representa uma string com interpolação, como "Idade: ${p.idade}".
+// Parts alterna trechos de texto (*StringLiteral) e as expressões embutidas, na ordem da fonte.
+type InterpolatedString struct {
+	Token token.Token // o token INTERP_START
+	Parts []Expression
+	Close token.Token // o token INTERP_END
+}
+
+func (is *InterpolatedString) expressionNode()      {}
+func (is *InterpolatedString) TokenLiteral() string { return is.Token.Literal }
+func (is *InterpolatedString) Pos() token.Position  { return is.Token.Pos }
+func (is *InterpolatedString) End() token.Position  { return is.Close.End }
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+	out.WriteString("\"")
+	for _, part := range is.Parts {
+		if lit, ok := part.(*StringLiteral); ok {
+			out.WriteString(lit.Value)
+			continue
+		}
+		out.WriteString("${" + part.String() + "}")
+	}
+	out.WriteString("\"")
+	return out.String()
+}
 
 type PrefixExpression struct {
 	Token    token.Token // O token do prefixo, ex: !
@@ -36,6 +100,8 @@ type PrefixExpression struct {
 
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() token.Position  { return pe.Token.Pos }
+func (pe *PrefixExpression) End() token.Position  { return endOf(pe.Right, pe.Token.End) }
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -53,23 +119,41 @@ type InfixExpression struct {
 }
 
 type AssignmentExpression struct {
-	Token token.Token // o token '='
-	Left  Expression
-	Value Expression
+	Token    token.Token // o token '=' ou de atribuição composta ('+=', '-=', ...)
+	Operator string      // "=" ou um operador composto: "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "<<=", ">>="
+	Left     Expression
+	Value    Expression
 }
 
 func (ae *AssignmentExpression) expressionNode()      {}
 func (ae *AssignmentExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignmentExpression) Pos() token.Position  { return posOf(ae.Left, ae.Token.Pos) }
+func (ae *AssignmentExpression) End() token.Position  { return endOf(ae.Value, ae.Token.End) }
 func (ae *AssignmentExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
 	out.WriteString(ae.Left.String())
-	out.WriteString(" = ")
+	out.WriteString(" " + ae.Operator + " ")
 	out.WriteString(ae.Value.String())
 	out.WriteString(")")
 	return out.String()
 }
 
+// PostfixExpression representa x++ e x--; o valor da expressão é o valor anterior de x.
+type PostfixExpression struct {
+	Token    token.Token // o token '++' ou '--'
+	Left     Expression
+	Operator string
+}
+
+func (pe *PostfixExpression) expressionNode()      {}
+func (pe *PostfixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PostfixExpression) Pos() token.Position  { return posOf(pe.Left, pe.Token.Pos) }
+func (pe *PostfixExpression) End() token.Position  { return pe.Token.End }
+func (pe *PostfixExpression) String() string {
+	return "(" + pe.Left.String() + pe.Operator + ")"
+}
+
 type IfExpression struct {
 	Token       token.Token // O token 'if'
 	Condition   Expression
@@ -79,6 +163,16 @@ type IfExpression struct {
 
 func (ie *IfExpression) expressionNode()      {}
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() token.Position  { return ie.Token.Pos }
+func (ie *IfExpression) End() token.Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+	if ie.Consequence != nil {
+		return ie.Consequence.End()
+	}
+	return endOf(ie.Condition, ie.Token.End)
+}
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("if")
@@ -96,11 +190,20 @@ type FunctionLiteral struct {
 	Token      token.Token
 	Name       *Identifier
 	Parameters []*Identifier
+	ReturnType Expression // nil quando a função não devolve valor
 	Body       *BlockStatement
+	Doc        string // doc comment ('///') de métodos declarados em um tipo
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() token.Position  { return fl.Token.Pos }
+func (fl *FunctionLiteral) End() token.Position {
+	if fl.Body != nil {
+		return fl.Body.End()
+	}
+	return fl.Token.End
+}
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 	params := []string{}
@@ -111,6 +214,9 @@ func (fl *FunctionLiteral) String() string {
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
+	if fl.ReturnType != nil {
+		out.WriteString(fl.ReturnType.String() + " ")
+	}
 	out.WriteString(fl.Body.String())
 	return out.String()
 }
@@ -119,10 +225,13 @@ type CallExpression struct {
 	Token     token.Token // O token '('
 	Function  Expression
 	Arguments []Expression
+	RParen    token.Token // O token ')'
 }
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Position  { return posOf(ce.Function, ce.Token.Pos) }
+func (ce *CallExpression) End() token.Position  { return ce.RParen.End }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 	args := []string{}
@@ -139,10 +248,13 @@ func (ce *CallExpression) String() string {
 type ArrayLiteral struct {
 	Token    token.Token // o token '['
 	Elements []Expression
+	RBracket token.Token // o token ']'
 }
 
 func (al *ArrayLiteral) expressionNode()      {}
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Position  { return al.Token.Pos }
+func (al *ArrayLiteral) End() token.Position  { return al.RBracket.End }
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
 	elements := []string{}
@@ -156,13 +268,16 @@ func (al *ArrayLiteral) String() string {
 }
 
 type IndexExpression struct {
-	Token token.Token // o token '['
-	Left  Expression
-	Index Expression
+	Token    token.Token // o token '['
+	Left     Expression
+	Index    Expression
+	RBracket token.Token // o token ']'
 }
 
 func (ie *IndexExpression) expressionNode()      {}
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() token.Position  { return posOf(ie.Left, ie.Token.Pos) }
+func (ie *IndexExpression) End() token.Position  { return ie.RBracket.End }
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -181,6 +296,13 @@ type MemberExpression struct {
 
 func (me *MemberExpression) expressionNode()      {}
 func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) Pos() token.Position  { return posOf(me.Object, me.Token.Pos) }
+func (me *MemberExpression) End() token.Position {
+	if me.Property != nil {
+		return me.Property.End()
+	}
+	return me.Token.End
+}
 func (me *MemberExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString(me.Object.String())
@@ -188,3 +310,84 @@ func (me *MemberExpression) String() string {
 	out.WriteString(me.Property.String())
 	return out.String()
 }
+
+// ArrayType é a expressão de tipo de um array de tamanho fixo, ex: [3]int ou [2][3]float.
+type ArrayType struct {
+	Token token.Token // o token '['
+	Len   Expression  // o número de elementos (um literal inteiro)
+	Elem  Expression  // o tipo dos elementos
+}
+
+func (at *ArrayType) expressionNode()      {}
+func (at *ArrayType) TokenLiteral() string { return at.Token.Literal }
+func (at *ArrayType) Pos() token.Position  { return at.Token.Pos }
+func (at *ArrayType) End() token.Position  { return endOf(at.Elem, at.Token.End) }
+func (at *ArrayType) String() string {
+	return "[" + at.Len.String() + "]" + at.Elem.String()
+}
+
+// FuncType é a expressão de tipo de um valor de função, ex: func(int, int) int.
+type FuncType struct {
+	Token      token.Token  // o token 'func'
+	Params     []Expression // os tipos dos parâmetros
+	ReturnType Expression   // nil quando a função não devolve valor
+	RParen     token.Token  // o token ')'
+}
+
+func (ft *FuncType) expressionNode()      {}
+func (ft *FuncType) TokenLiteral() string { return ft.Token.Literal }
+func (ft *FuncType) Pos() token.Position  { return ft.Token.Pos }
+func (ft *FuncType) End() token.Position  { return endOf(ft.ReturnType, ft.RParen.End) }
+func (ft *FuncType) String() string {
+	params := []string{}
+	for _, p := range ft.Params {
+		params = append(params, p.String())
+	}
+	out := "func(" + strings.Join(params, ", ") + ")"
+	if ft.ReturnType != nil {
+		out += " " + ft.ReturnType.String()
+	}
+	return out
+}
+
+// TupleExpression é a lista de valores de um 'return' com múltiplos valores: return q, r.
+type TupleExpression struct {
+	Token    token.Token // o primeiro token da lista
+	Elements []Expression
+}
+
+func (te *TupleExpression) expressionNode()      {}
+func (te *TupleExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *TupleExpression) Pos() token.Position  { return te.Token.Pos }
+func (te *TupleExpression) End() token.Position {
+	if len(te.Elements) > 0 {
+		return te.Elements[len(te.Elements)-1].End()
+	}
+	return te.Token.End
+}
+func (te *TupleExpression) String() string {
+	elements := []string{}
+	for _, el := range te.Elements {
+		elements = append(elements, el.String())
+	}
+	return strings.Join(elements, ", ")
+}
+
+// TupleType é o tipo de retorno de uma função que devolve múltiplos valores, ex: (int, int).
+type TupleType struct {
+	Token  token.Token // o token '('
+	Types  []Expression
+	RParen token.Token // o token ')'
+}
+
+func (tt *TupleType) expressionNode()      {}
+func (tt *TupleType) TokenLiteral() string { return tt.Token.Literal }
+func (tt *TupleType) Pos() token.Position  { return tt.Token.Pos }
+func (tt *TupleType) End() token.Position  { return tt.RParen.End }
+func (tt *TupleType) String() string {
+	types := []string{}
+	for _, t := range tt.Types {
+		types = append(types, t.String())
+	}
+	return "(" + strings.Join(types, ", ") + ")"
+}
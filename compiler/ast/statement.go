@@ -3,12 +3,26 @@ package ast
 
 import (
 	"bytes"
+	"path"
 	"strings"
 	"taquion/compiler/token"
 )
 
 // --- Nós de Declaração ---
 
+// BadStmt marca uma instrução que não pôde ser analisada. O parser a usa no lugar do nó
+// esperado para que a AST nunca contenha nil, e segue a análise a partir do próximo ponto seguro.
+type BadStmt struct {
+	From token.Token // primeiro token da instrução
+	To   token.Token // último token descartado
+}
+
+func (bs *BadStmt) statementNode()       {}
+func (bs *BadStmt) TokenLiteral() string { return bs.From.Literal }
+func (bs *BadStmt) Pos() token.Position  { return bs.From.Pos }
+func (bs *BadStmt) End() token.Position  { return bs.To.End }
+func (bs *BadStmt) String() string       { return "<instrução inválida>" }
+
 type PackageStatement struct {
 	Token token.Token
 	Name  *Identifier
@@ -16,25 +30,81 @@ type PackageStatement struct {
 
 func (ps *PackageStatement) statementNode()       {}
 func (ps *PackageStatement) TokenLiteral() string { return ps.Token.Literal }
+func (ps *PackageStatement) Pos() token.Position  { return ps.Token.Pos }
+func (ps *PackageStatement) End() token.Position {
+	if ps.Name != nil {
+		return ps.Name.End()
+	}
+	return ps.Token.End
+}
 func (ps *PackageStatement) String() string {
-	initLogger()
-	logger.Printf("Gerando string para PackageStatement: %s %s\n", ps.Token.Literal, ps.Name.Value)
 	return ps.TokenLiteral() + " " + ps.Name.String() + ";"
 }
 
+// ImportStatement importa um pacote pelo caminho relativo à raiz do módulo: import "matematica".
+// Os nomes do pacote ficam acessíveis pelo último segmento do caminho (matematica.soma).
+type ImportStatement struct {
+	Token token.Token // o token 'import'
+	Path  *StringLiteral
+}
+
+func (is *ImportStatement) statementNode()       {}
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *ImportStatement) Pos() token.Position  { return is.Token.Pos }
+func (is *ImportStatement) End() token.Position {
+	if is.Path != nil {
+		return is.Path.End()
+	}
+	return is.Token.End
+}
+func (is *ImportStatement) String() string {
+	return is.TokenLiteral() + " \"" + is.Path.Value + "\";"
+}
+
+// PackageName devolve o nome pelo qual o pacote importado é referenciado no código.
+func (is *ImportStatement) PackageName() string {
+	return path.Base(is.Path.Value)
+}
+
 type LetStatement struct {
 	Token token.Token
 	Name  *Identifier
-	Value Expression
+	Names []*Identifier // todos os nomes de uma desestruturação (let q, r = f()); nil com um só nome
+	Type  Expression    // tipo anotado (nil quando inferido do valor)
+	Value Expression    // nil quando a variável começa com o valor zero do tipo
 }
 
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Pos() token.Position  { return ls.Token.Pos }
+func (ls *LetStatement) End() token.Position {
+	if ls.Value != nil {
+		return ls.Value.End()
+	}
+	if ls.Type != nil {
+		return ls.Type.End()
+	}
+	if ls.Name != nil {
+		return ls.Name.End()
+	}
+	return ls.Token.End
+}
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
-	out.WriteString(ls.TokenLiteral() + " " + ls.Name.String() + " = ")
+	if len(ls.Names) > 0 {
+		names := []string{}
+		for _, n := range ls.Names {
+			names = append(names, n.String())
+		}
+		out.WriteString(ls.TokenLiteral() + " " + strings.Join(names, ", "))
+	} else {
+		out.WriteString(ls.TokenLiteral() + " " + ls.Name.String())
+	}
+	if ls.Type != nil {
+		out.WriteString(": " + ls.Type.String())
+	}
 	if ls.Value != nil {
-		out.WriteString(ls.Value.String())
+		out.WriteString(" = " + ls.Value.String())
 	}
 	out.WriteString(";")
 	return out.String()
@@ -43,18 +113,31 @@ func (ls *LetStatement) String() string {
 type ConstStatement struct {
 	Token token.Token
 	Name  *Identifier
+	Type  Expression // tipo anotado (nil quando inferido do valor)
 	Value Expression
+	Doc   string // doc comment ('///') que precede a declaração
 }
 
 func (cs *ConstStatement) statementNode()       {}
 func (cs *ConstStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ConstStatement) Pos() token.Position  { return cs.Token.Pos }
+func (cs *ConstStatement) End() token.Position {
+	if cs.Value != nil {
+		return cs.Value.End()
+	}
+	if cs.Name != nil {
+		return cs.Name.End()
+	}
+	return cs.Token.End
+}
 func (cs *ConstStatement) String() string {
-	initLogger()
-	logger.Printf("Gerando string para ConstStatement: %s\n", cs.Name.Value)
 	var out bytes.Buffer
-	out.WriteString(cs.TokenLiteral() + " " + cs.Name.String() + " = ")
+	out.WriteString(cs.TokenLiteral() + " " + cs.Name.String())
+	if cs.Type != nil {
+		out.WriteString(": " + cs.Type.String())
+	}
 	if cs.Value != nil {
-		out.WriteString(cs.Value.String())
+		out.WriteString(" = " + cs.Value.String())
 	}
 	out.WriteString(";")
 	return out.String()
@@ -67,9 +150,9 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Position  { return rs.Token.Pos }
+func (rs *ReturnStatement) End() token.Position  { return endOf(rs.ReturnValue, rs.Token.End) }
 func (rs *ReturnStatement) String() string {
-	initLogger()
-	logger.Println("Gerando string para ReturnStatement")
 	var out bytes.Buffer
 	out.WriteString(rs.TokenLiteral() + " ")
 	if rs.ReturnValue != nil {
@@ -86,9 +169,9 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Position  { return posOf(es.Expression, es.Token.Pos) }
+func (es *ExpressionStatement) End() token.Position  { return endOf(es.Expression, es.Token.End) }
 func (es *ExpressionStatement) String() string {
-	initLogger()
-	logger.Println("Gerando string para ExpressionStatement")
 	if es.Expression != nil {
 		return es.Expression.String()
 	}
@@ -98,10 +181,21 @@ func (es *ExpressionStatement) String() string {
 type BlockStatement struct {
 	Token      token.Token // o token '{'
 	Statements []Statement
+	RBrace     token.Token // o token '}'
 }
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BlockStatement) End() token.Position {
+	if bs.RBrace.End.IsValid() {
+		return bs.RBrace.End
+	}
+	if len(bs.Statements) > 0 {
+		return bs.Statements[len(bs.Statements)-1].End()
+	}
+	return bs.Token.End
+}
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 	for _, s := range bs.Statements {
@@ -114,14 +208,24 @@ type FunctionDeclaration struct {
 	Token      token.Token
 	Name       *Identifier
 	Parameters []*Identifier
+	ReturnType Expression // nil quando a função não devolve valor
 	Body       *BlockStatement
+	Doc        string // doc comment ('///') que precede a declaração
 }
 
 func (fd *FunctionDeclaration) statementNode()       {}
 func (fd *FunctionDeclaration) TokenLiteral() string { return fd.Token.Literal }
+func (fd *FunctionDeclaration) Pos() token.Position  { return fd.Token.Pos }
+func (fd *FunctionDeclaration) End() token.Position {
+	if fd.Body != nil {
+		return fd.Body.End()
+	}
+	if fd.Name != nil {
+		return fd.Name.End()
+	}
+	return fd.Token.End
+}
 func (fd *FunctionDeclaration) String() string {
-	initLogger()
-	logger.Printf("Gerando string para FunctionDeclaration: %s\n", fd.Name.Value)
 	var out bytes.Buffer
 	out.WriteString(fd.TokenLiteral() + " " + fd.Name.String() + "(")
 	params := []string{}
@@ -130,6 +234,9 @@ func (fd *FunctionDeclaration) String() string {
 	}
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
+	if fd.ReturnType != nil {
+		out.WriteString(fd.ReturnType.String() + " ")
+	}
 	out.WriteString(fd.Body.String())
 	return out.String()
 }
@@ -142,6 +249,13 @@ type WhileStatement struct {
 
 func (ws *WhileStatement) statementNode()       {}
 func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) Pos() token.Position  { return ws.Token.Pos }
+func (ws *WhileStatement) End() token.Position {
+	if ws.Body != nil {
+		return ws.Body.End()
+	}
+	return endOf(ws.Condition, ws.Token.End)
+}
 func (ws *WhileStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString("while")
@@ -151,6 +265,125 @@ func (ws *WhileStatement) String() string {
 	return out.String()
 }
 
+// ForStatement é o laço no estilo C: for init; cond; post { ... }.
+// Também cobre 'for cond { ... }' e 'for { ... }', com as partes ausentes nulas.
+type ForStatement struct {
+	Token     token.Token // o token 'for'
+	Init      Statement   // executado uma vez antes do laço (pode ser nil)
+	Condition Expression  // nil repete para sempre
+	Post      Statement   // executado ao fim de cada iteração (pode ser nil)
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) Pos() token.Position  { return fs.Token.Pos }
+func (fs *ForStatement) End() token.Position {
+	if fs.Body != nil {
+		return fs.Body.End()
+	}
+	return fs.Token.End
+}
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("for ")
+	if fs.Init != nil {
+		out.WriteString(strings.TrimSuffix(fs.Init.String(), ";"))
+	}
+	out.WriteString("; ")
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+	out.WriteString("; ")
+	if fs.Post != nil {
+		out.WriteString(fs.Post.String())
+	}
+	out.WriteString(" ")
+	out.WriteString(fs.Body.String())
+	return out.String()
+}
+
+// ForInStatement percorre os elementos de um array: for v in arr { ... } ou for i, v in arr { ... }.
+type ForInStatement struct {
+	Token    token.Token // o token 'for'
+	Index    *Identifier // a variável do índice (nil na forma 'for v in arr')
+	Value    *Identifier // a variável do elemento
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForInStatement) statementNode()       {}
+func (fs *ForInStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForInStatement) Pos() token.Position  { return fs.Token.Pos }
+func (fs *ForInStatement) End() token.Position {
+	if fs.Body != nil {
+		return fs.Body.End()
+	}
+	return fs.Token.End
+}
+func (fs *ForInStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("for ")
+	if fs.Index != nil {
+		out.WriteString(fs.Index.String() + ", ")
+	}
+	out.WriteString(fs.Value.String() + " in " + fs.Iterable.String() + " ")
+	out.WriteString(fs.Body.String())
+	return out.String()
+}
+
+// MatchStatement escolhe um braço pelo valor do assunto: match x { 1, 2 => ..., _ => ... }.
+// Não há fallthrough: só o braço escolhido é executado.
+type MatchStatement struct {
+	Token   token.Token // o token 'match'
+	Subject Expression
+	Arms    []*MatchArm
+	RBrace  token.Token // o token '}'
+}
+
+func (ms *MatchStatement) statementNode()       {}
+func (ms *MatchStatement) TokenLiteral() string { return ms.Token.Literal }
+func (ms *MatchStatement) Pos() token.Position  { return ms.Token.Pos }
+func (ms *MatchStatement) End() token.Position {
+	if ms.RBrace.End.IsValid() {
+		return ms.RBrace.End
+	}
+	return ms.Token.End
+}
+func (ms *MatchStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("match " + ms.Subject.String() + " { ")
+	arms := []string{}
+	for _, arm := range ms.Arms {
+		arms = append(arms, arm.String())
+	}
+	out.WriteString(strings.Join(arms, "; "))
+	out.WriteString(" }")
+	return out.String()
+}
+
+// MatchArm é um braço de um match: os valores aceitos (ou '_') e o código executado.
+type MatchArm struct {
+	Token    token.Token  // o primeiro token do braço
+	Patterns []Expression // os valores do caso; vazio no braço padrão
+	Default  bool         // o braço '_', escolhido quando nenhum outro casa
+	Body     *BlockStatement
+}
+
+func (ma *MatchArm) TokenLiteral() string { return ma.Token.Literal }
+func (ma *MatchArm) Pos() token.Position  { return ma.Token.Pos }
+func (ma *MatchArm) End() token.Position  { return endOf(ma.Body, ma.Token.End) }
+func (ma *MatchArm) String() string {
+	patterns := []string{}
+	for _, p := range ma.Patterns {
+		patterns = append(patterns, p.String())
+	}
+	if ma.Default {
+		patterns = append(patterns, "_")
+	}
+	return strings.Join(patterns, ", ") + " => " + ma.Body.String()
+}
+
 type BreakStatement struct {
 	Token token.Token // o token 'break'
 }
@@ -158,6 +391,8 @@ type BreakStatement struct {
 func (bs *BreakStatement) statementNode()       {}
 func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
 func (bs *BreakStatement) String() string       { return bs.Token.Literal + ";" }
+func (bs *BreakStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BreakStatement) End() token.Position  { return bs.Token.End }
 
 type ContinueStatement struct {
 	Token token.Token // o token 'continue'
@@ -166,17 +401,22 @@ type ContinueStatement struct {
 func (cs *ContinueStatement) statementNode()       {}
 func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
 func (cs *ContinueStatement) String() string       { return cs.Token.Literal + ";" }
+func (cs *ContinueStatement) Pos() token.Position  { return cs.Token.Pos }
+func (cs *ContinueStatement) End() token.Position  { return cs.Token.End }
 
 type TypeDeclaration struct {
 	Token   token.Token
 	Name    *Identifier
 	Fields  []*StructField     // <- novo!
 	Methods []*FunctionLiteral // <- novo!
+	RBrace  token.Token        // o token '}' que fecha a declaração
+	Doc     string             // doc comment ('///') que precede a declaração
 }
 
 type StructField struct {
 	Name *Identifier
-	Type Expression // pode ser um Identifier ou tipo composto no futuro
+	Type Expression // um Identifier ou um ArrayType
+	Doc  string     // doc comment ('///') que precede o campo
 }
 
 func (sf *StructField) String() string {
@@ -189,8 +429,13 @@ func (sf *StructField) String() string {
 	return out.String()
 }
 
+func (sf *StructField) Pos() token.Position { return sf.Name.Pos() }
+func (sf *StructField) End() token.Position { return endOf(sf.Type, sf.Name.End()) }
+
 func (td *TypeDeclaration) statementNode()       {}
 func (td *TypeDeclaration) TokenLiteral() string { return td.Token.Literal }
+func (td *TypeDeclaration) Pos() token.Position  { return td.Token.Pos }
+func (td *TypeDeclaration) End() token.Position  { return td.RBrace.End }
 
 func (td *TypeDeclaration) String() string {
 	var out bytes.Buffer
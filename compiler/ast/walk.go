@@ -0,0 +1,118 @@
+// Arquivo: ast/walk.go
+package ast
+
+// Inspect percorre a árvore a partir de node em profundidade, chamando f para cada nó antes dos
+// seus filhos. Quando f devolve false, os filhos daquele nó não são visitados. Nós de tipo
+// (anotações e tipos de retorno) não são visitados.
+func Inspect(node Node, f func(Node) bool) {
+	if isNil(node) || !f(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		for _, s := range n.Statements {
+			Inspect(s, f)
+		}
+
+	// Instruções
+	case *LetStatement:
+		Inspect(n.Value, f)
+	case *ConstStatement:
+		Inspect(n.Value, f)
+	case *ReturnStatement:
+		Inspect(n.ReturnValue, f)
+	case *ExpressionStatement:
+		Inspect(n.Expression, f)
+	case *BlockStatement:
+		for _, s := range n.Statements {
+			Inspect(s, f)
+		}
+	case *FunctionDeclaration:
+		Inspect(n.Body, f)
+	case *WhileStatement:
+		Inspect(n.Condition, f)
+		Inspect(n.Body, f)
+	case *ForStatement:
+		Inspect(n.Init, f)
+		Inspect(n.Condition, f)
+		Inspect(n.Post, f)
+		Inspect(n.Body, f)
+	case *ForInStatement:
+		Inspect(n.Iterable, f)
+		Inspect(n.Body, f)
+	case *MatchStatement:
+		Inspect(n.Subject, f)
+		for _, arm := range n.Arms {
+			for _, p := range arm.Patterns {
+				Inspect(p, f)
+			}
+			Inspect(arm.Body, f)
+		}
+	case *TypeDeclaration:
+		for _, m := range n.Methods {
+			Inspect(m, f)
+		}
+
+	// Expressões
+	case *InterpolatedString:
+		for _, part := range n.Parts {
+			Inspect(part, f)
+		}
+	case *PrefixExpression:
+		Inspect(n.Right, f)
+	case *InfixExpression:
+		Inspect(n.Left, f)
+		Inspect(n.Right, f)
+	case *AssignmentExpression:
+		Inspect(n.Left, f)
+		Inspect(n.Value, f)
+	case *PostfixExpression:
+		Inspect(n.Left, f)
+	case *IfExpression:
+		Inspect(n.Condition, f)
+		Inspect(n.Consequence, f)
+		Inspect(n.Alternative, f)
+	case *FunctionLiteral:
+		Inspect(n.Body, f)
+	case *CallExpression:
+		Inspect(n.Function, f)
+		for _, a := range n.Arguments {
+			Inspect(a, f)
+		}
+	case *ArrayLiteral:
+		for _, el := range n.Elements {
+			Inspect(el, f)
+		}
+	case *IndexExpression:
+		Inspect(n.Left, f)
+		Inspect(n.Index, f)
+	case *MemberExpression:
+		// A propriedade é um nome de campo, não uma referência a uma variável.
+		Inspect(n.Object, f)
+	case *TupleExpression:
+		for _, el := range n.Elements {
+			Inspect(el, f)
+		}
+	case *CompositeLiteral:
+		for _, kv := range n.Fields {
+			Inspect(kv.Value, f)
+		}
+	}
+}
+
+// isNil informa se o nó está ausente, inclusive quando é um ponteiro nulo guardado na interface
+// (ex: o Alternative de um if sem else).
+func isNil(node Node) bool {
+	switch n := node.(type) {
+	case nil:
+		return true
+	case *BlockStatement:
+		return n == nil
+	case *Identifier:
+		return n == nil
+	case *FunctionLiteral:
+		return n == nil
+	}
+	return false
+}
@@ -3,61 +3,120 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
-	"taquion/compiler/ast"
 	"taquion/compiler/codegen"
-	"taquion/compiler/lexer"
-	"taquion/compiler/parser"
-	"taquion/compiler/token"
+	"taquion/compiler/loader"
+	"taquion/compiler/trace"
 
 	"github.com/taquion-lang/go-llvm"
 )
 
-func main() {
-	// Garante que todos os loggers globais sejam fechados na saída.
-	defer token.CloseLogger()
-	defer ast.CloseLogger()
+// options reúne os argumentos de linha de comando do taquionc.
+type options struct {
+	input    string
+	output   string
+	trace    []trace.Phase
+	traceOut string
+}
 
-	// --- Processamento de Argumentos Simples (Compatível com o Tester) ---
-	if len(os.Args) < 2 {
-		fmt.Println("Uso: taquionc <arquivo.taq>")
-		os.Exit(1)
+const usage = "Uso: taquionc [--trace=lexer,parser,codegen] [--trace-out=arquivo] <arquivo.taq|diretório> [-o saida.ll]"
+
+// parseArgs interpreta os argumentos. Flags podem aparecer antes ou depois do arquivo de entrada,
+// mantendo a forma 'taquionc <arquivo.taq> -o <saida>' usada pelo Makefile e pelo tester.
+func parseArgs(args []string) (options, error) {
+	// O nome do arquivo de saída padrão é fixo para ser compatível com o Makefile e o script de teste.
+	opts := options{output: "../build/output.ll"}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-o" || arg == "--trace-out":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s exige um argumento", arg)
+			}
+			i++
+			if arg == "-o" {
+				opts.output = args[i]
+			} else {
+				opts.traceOut = args[i]
+			}
+		case strings.HasPrefix(arg, "--trace-out="):
+			opts.traceOut = strings.TrimPrefix(arg, "--trace-out=")
+		case arg == "--trace":
+			opts.trace = trace.Phases
+		case strings.HasPrefix(arg, "--trace="):
+			phases, err := trace.ParsePhases(strings.TrimPrefix(arg, "--trace="))
+			if err != nil {
+				return opts, err
+			}
+			opts.trace = phases
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			return opts, fmt.Errorf("opção desconhecida: %s", arg)
+		case opts.input == "":
+			opts.input = arg
+		default:
+			return opts, fmt.Errorf("argumento inesperado: %s", arg)
+		}
 	}
-	inputFilePath := os.Args[1]
+	if opts.input == "" {
+		return opts, fmt.Errorf("nenhum arquivo de entrada")
+	}
+	return opts, nil
+}
 
-	// O nome do arquivo de saída é fixo para ser compatível com o Makefile e o script de teste.
-	// O script de teste lida com a renomeação e movimentação dos arquivos.
-	outputFilename := "../build/output.ll"
-	if len(os.Args) > 3 && os.Args[2] == "-o" {
-		outputFilename = os.Args[3]
+// newTracer cria o tracer pedido pelas opções. Sem --trace o rastreamento fica desligado;
+// sem --trace-out as mensagens vão para a saída de erro.
+func newTracer(opts options) (trace.Tracer, func(), error) {
+	if len(opts.trace) == 0 {
+		return trace.Nop, func() {}, nil
+	}
+	if opts.traceOut == "" {
+		return trace.New(os.Stderr, opts.trace...), func() {}, nil
+	}
+	f, err := os.Create(opts.traceOut)
+	if err != nil {
+		return nil, nil, err
 	}
+	return trace.New(f, opts.trace...), func() { f.Close() }, nil
+}
 
-	// --- Pipeline de Compilação ---
-	sourceCode, err := os.ReadFile(inputFilePath)
+func main() {
+	opts, err := parseArgs(os.Args[1:])
 	if err != nil {
-		fmt.Printf("Erro ao ler o arquivo %s: %s\n", inputFilePath, err)
+		fmt.Println(err)
+		fmt.Println(usage)
 		os.Exit(1)
 	}
+	inputFilePath, outputFilename := opts.input, opts.output
 
-	l := lexer.New(string(sourceCode))
-	p := parser.New(l)
-	program := p.ParseProgram()
+	tracer, closeTrace, err := newTracer(opts)
+	if err != nil {
+		fmt.Printf("Erro ao abrir o arquivo de trace: %s\n", err)
+		os.Exit(1)
+	}
+	defer closeTrace()
 
-	if len(p.Errors()) != 0 {
+	// --- Pipeline de Compilação ---
+	// O loader analisa o arquivo (ou diretório) de entrada e todos os pacotes importados.
+	packages, errs := loader.Load(inputFilePath, tracer)
+	if len(errs) != 0 {
 		fmt.Println("Encontrados erros de parsing:")
-		for _, msg := range p.Errors() {
+		for _, msg := range errs {
 			fmt.Println("\t" + msg)
 		}
 		os.Exit(1)
 	}
 
 	fmt.Println("--- AST Gerada ---")
-	fmt.Println(program.String())
+	for _, pkg := range packages {
+		for _, program := range pkg.Files {
+			fmt.Println(program.String())
+		}
+	}
 	fmt.Println("--------------------")
 
-	generator := codegen.NewCodeGenerator()
-	defer generator.Close() // Isso chama codegen.CloseLogger() internamente
-	module := generator.Generate(program)
+	generator := codegen.NewCodeGenerator(tracer)
+	module := generator.GeneratePackages(packages)
 
 	// Verifica se o módulo LLVM é válido
 	if err := llvm.VerifyModule(module, llvm.PrintMessageAction); err != nil {
@@ -21,4 +21,10 @@ func (c *CodeGenerator) declareCFunctions() {
 	strcpyType := llvm.FunctionType(i8PtrType, []llvm.Type{i8PtrType, i8PtrType}, false)
 	c.strcpyFunc = llvm.AddFunction(c.module, "strcpy", strcpyType)
 	c.strcatFunc = llvm.AddFunction(c.module, "strcat", strcpyType)
+
+	strcmpType := llvm.FunctionType(c.context.Int32Type(), []llvm.Type{i8PtrType, i8PtrType}, false)
+	c.strcmpFunc = llvm.AddFunction(c.module, "strcmp", strcmpType)
+
+	c.snprintfType = llvm.FunctionType(c.context.Int32Type(), []llvm.Type{i8PtrType, sizeType, i8PtrType}, true)
+	c.snprintfFunc = llvm.AddFunction(c.module, "snprintf", c.snprintfType)
 }
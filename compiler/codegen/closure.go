@@ -0,0 +1,311 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+	"taquion/compiler/ast"
+
+	"github.com/taquion-lang/go-llvm"
+)
+
+// Uma closure é o par {ponteiro da função, ponteiro do ambiente}. A função gerada para uma função
+// anônima recebe o ambiente como primeiro parâmetro; o ambiente é uma struct alocada no heap com
+// os endereços das variáveis capturadas. Essas variáveis vivem no heap (ver allocLocal), então a
+// função de fora e a closure leem e escrevem a mesma variável, mesmo depois que a de fora retorna.
+
+// closureSignature descreve o tipo de uma closure, indexado pelo nome do tipo na fonte
+// ("func(int) int"): a assinatura LLVM da função (com o ambiente) e os nomes dos tipos dos
+// parâmetros e do retorno.
+type closureSignature struct {
+	fnType         llvm.Type
+	paramTypeNames []string
+	returnTypeName string
+}
+
+// capture é um símbolo local de fora usado pelo corpo de uma função anônima.
+type capture struct {
+	name  string
+	entry SymbolEntry
+}
+
+// closureType devolve o tipo LLVM dos valores de closure: { ptr, ptr }.
+func (c *CodeGenerator) closureType() llvm.Type {
+	ptr := llvm.PointerType(c.context.Int8Type(), 0)
+	return c.context.StructType([]llvm.Type{ptr, ptr}, false)
+}
+
+// funcTypeName monta o nome na fonte do tipo de uma função, ex: "func(int, string) bool".
+func funcTypeName(params []string, returnTypeName string) string {
+	name := "func(" + strings.Join(params, ", ") + ")"
+	if returnTypeName != "" {
+		name += " " + returnTypeName
+	}
+	return name
+}
+
+// registerFuncType registra a assinatura do tipo de função typeName e devolve o tipo LLVM da
+// função que implementa uma closure desse tipo: os parâmetros, precedidos pelo ambiente.
+func (c *CodeGenerator) registerFuncType(typeName string, retType llvm.Type, paramTypes []llvm.Type,
+	paramTypeNames []string, retTypeName string) llvm.Type {
+	if sig, ok := c.closureSignatures[typeName]; ok {
+		return sig.fnType
+	}
+	envPtrType := llvm.PointerType(c.context.Int8Type(), 0)
+	fnType := llvm.FunctionType(retType, append([]llvm.Type{envPtrType}, paramTypes...), false)
+	c.closureSignatures[typeName] = closureSignature{fnType: fnType, paramTypeNames: paramTypeNames, returnTypeName: retTypeName}
+	return fnType
+}
+
+// funcTypeMatches informa se expr pode ser usada onde se espera um valor do tipo targetName.
+// Todos os valores de função têm o mesmo tipo LLVM (closureType), então a assinatura é conferida
+// pelo nome do tipo na fonte; para os demais tipos o resultado é sempre true.
+func (c *CodeGenerator) funcTypeMatches(expr ast.Expression, targetName string) bool {
+	if _, isFunc := c.closureSignatures[targetName]; !isFunc {
+		return true
+	}
+	return c.exprTypeName(expr) == targetName
+}
+
+// lookupFuncType resolve um tipo 'func(T...) R' da fonte: registra a sua assinatura e devolve
+// o tipo dos valores de closure.
+func (c *CodeGenerator) lookupFuncType(node *ast.FuncType) llvm.Type {
+	paramTypes := make([]llvm.Type, len(node.Params))
+	paramTypeNames := make([]string, len(node.Params))
+	for i, p := range node.Params {
+		paramTypes[i], paramTypeNames[i] = c.lookupLLVMType(p), c.typeName(p)
+	}
+	retType, retTypeName := c.context.VoidType(), ""
+	if node.ReturnType != nil {
+		retType, retTypeName = c.lookupLLVMType(node.ReturnType), c.typeName(node.ReturnType)
+	}
+	c.registerFuncType(c.typeName(node), retType, paramTypes, paramTypeNames, retTypeName)
+	return c.closureType()
+}
+
+// functionValue devolve uma função declarada como valor de closure. Como as closures recebem o
+// ambiente como primeiro parâmetro, a função é chamada por um adaptador (gerado uma vez por
+// função) que descarta o ambiente.
+func (c *CodeGenerator) functionValue(entry SymbolEntry) llvm.Value {
+	fn, fnType := entry.Value, entry.Typ
+	envPtrType := llvm.PointerType(c.context.Int8Type(), 0)
+
+	wrapperName := fn.Name() + ".closure"
+	wrapper := c.module.NamedFunction(wrapperName)
+	if wrapper.IsNil() {
+		wrapperType := llvm.FunctionType(fnType.ReturnType(), append([]llvm.Type{envPtrType}, fnType.ParamTypes()...), false)
+		wrapper = llvm.AddFunction(c.module, wrapperName, wrapperType)
+		wrapper.SetLinkage(llvm.InternalLinkage)
+
+		savedBlock := c.builder.GetInsertBlock()
+		c.builder.SetInsertPointAtEnd(c.context.AddBasicBlock(wrapper, "entry"))
+		if fnType.ReturnType().TypeKind() == llvm.VoidTypeKind {
+			c.builder.CreateCall(fnType, fn, wrapper.Params()[1:], "")
+			c.builder.CreateRetVoid()
+		} else {
+			c.builder.CreateRet(c.builder.CreateCall(fnType, fn, wrapper.Params()[1:], "calltmp"))
+		}
+		if !savedBlock.IsNil() {
+			c.builder.SetInsertPointAtEnd(savedBlock)
+		}
+	}
+	return c.context.ConstStruct([]llvm.Value{wrapper, llvm.ConstNull(envPtrType)}, false)
+}
+
+// literalTypeName devolve o nome do tipo de uma função anônima.
+func (c *CodeGenerator) literalTypeName(node *ast.FunctionLiteral) string {
+	params := make([]string, len(node.Parameters))
+	for i, p := range node.Parameters {
+		if p.Type == nil {
+			return ""
+		}
+		params[i] = c.typeName(p.Type)
+	}
+	return funcTypeName(params, c.returnTypeName(node.ReturnType))
+}
+
+// genFunctionLiteral gera uma função anônima e devolve o valor da closure.
+func (c *CodeGenerator) genFunctionLiteral(node *ast.FunctionLiteral) llvm.Value {
+	c.logTracef("Gerando FunctionLiteral")
+	envPtrType := llvm.PointerType(c.context.Int8Type(), 0)
+
+	paramTypes := []llvm.Type{envPtrType}
+	paramTypeNames := []string{}
+	for _, p := range node.Parameters {
+		if p.Type == nil {
+			panic(errorAt(p, "o parâmetro '%s' da função anônima não possui um tipo", p.Value))
+		}
+		paramTypes = append(paramTypes, c.lookupLLVMType(p.Type))
+		paramTypeNames = append(paramTypeNames, c.typeName(p.Type))
+	}
+	retType, retTypeName := c.context.VoidType(), ""
+	if node.ReturnType != nil {
+		retType, retTypeName = c.lookupLLVMType(node.ReturnType), c.typeName(node.ReturnType)
+	}
+	fnType := c.registerFuncType(c.literalTypeName(node), retType, paramTypes[1:], paramTypeNames, retTypeName)
+
+	captures, constants := c.findCaptures(node)
+	fieldTypes := make([]llvm.Type, len(captures))
+	for i := range captures {
+		fieldTypes[i] = envPtrType
+	}
+	envType := c.context.StructType(fieldTypes, false)
+
+	// O ambiente é preenchido na função de fora, antes de gerar o corpo.
+	env := llvm.ConstNull(envPtrType)
+	if len(captures) > 0 {
+		mallocType := llvm.FunctionType(envPtrType, []llvm.Type{c.context.Int64Type()}, false)
+		env = c.builder.CreateCall(mallocType, c.mallocFunc, []llvm.Value{llvm.SizeOf(envType)}, "closure_env")
+		for i, cp := range captures {
+			fieldPtr := c.builder.CreateStructGEP(envType, env, i, cp.name+"_env_ptr")
+			c.builder.CreateStore(cp.entry.Ptr, fieldPtr)
+		}
+	}
+
+	c.lambdaCount++
+	function := llvm.AddFunction(c.module, c.mangle(fmt.Sprintf("lambda.%d", c.lambdaCount)), fnType)
+	function.SetLinkage(llvm.InternalLinkage)
+	c.genLambdaBody(node, function, captures, constants, envType, retType, retTypeName)
+
+	if len(captures) == 0 {
+		return c.context.ConstStruct([]llvm.Value{function, env}, false)
+	}
+	closure := c.builder.CreateInsertValue(llvm.Undef(c.closureType()), function, 0, "closure_fn")
+	return c.builder.CreateInsertValue(closure, env, 1, "closure")
+}
+
+// genLambdaBody gera o corpo da função anônima. Lá dentro só são visíveis os globais, as variáveis
+// capturadas (lidas e escritas no ambiente) e os parâmetros; o estado da função de fora é
+// restaurado ao final.
+func (c *CodeGenerator) genLambdaBody(node *ast.FunctionLiteral, function llvm.Value,
+	captures, constants []capture, envType llvm.Type, retType llvm.Type, retTypeName string) {
+	savedBlock := c.builder.GetInsertBlock()
+	savedScopes, savedLoops := c.symbolTable, c.loops
+	savedRetType, savedRetTypeName := c.currentFunctionReturnType, c.currentFunctionReturnTypeName
+	savedBoxed := c.boxed
+	defer func() {
+		c.builder.SetInsertPointAtEnd(savedBlock)
+		c.symbolTable, c.loops = savedScopes, savedLoops
+		c.currentFunctionReturnType, c.currentFunctionReturnTypeName = savedRetType, savedRetTypeName
+		c.boxed = savedBoxed
+	}()
+	c.boxed = capturedNames(node.Body)
+
+	c.symbolTable = []map[string]SymbolEntry{savedScopes[0], make(map[string]SymbolEntry)}
+	c.loops = nil
+	c.currentFunctionReturnType, c.currentFunctionReturnTypeName = retType, retTypeName
+	c.builder.SetInsertPointAtEnd(c.context.AddBasicBlock(function, "entry"))
+
+	for _, cp := range constants {
+		c.setSymbol(cp.name, cp.entry)
+	}
+	env := function.Param(0)
+	env.SetName("env")
+	for i, cp := range captures {
+		// O símbolo aponta para a própria variável de fora; arrays continuam com o ponteiro
+		// para o array guardado nela, como numa variável local.
+		fieldPtr := c.builder.CreateStructGEP(envType, env, i, cp.name+"_env_ptr")
+		entry := cp.entry
+		entry.Ptr = c.builder.CreateLoad(env.Type(), fieldPtr, cp.name+"_ref")
+		c.setSymbol(cp.name, entry)
+	}
+	for i, param := range node.Parameters {
+		paramValue := function.Param(i + 1)
+		paramValue.SetName(param.Value)
+		c.defineLocal(param, paramValue, c.typeName(param.Type), false)
+	}
+
+	c.genStatement(node.Body)
+	c.genFunctionEnd(node, "a função anônima")
+}
+
+// findCaptures lista, na ordem em que aparecem no corpo, as variáveis locais da função de fora
+// usadas pela função anônima. Globais não precisam ser capturados, e os símbolos locais sem
+// endereço (constantes com valor conhecido) voltam em constants, para serem copiados direto
+// para o escopo da função anônima.
+func (c *CodeGenerator) findCaptures(node *ast.FunctionLiteral) (captures, constants []capture) {
+	params := make(map[string]bool)
+	for _, p := range node.Parameters {
+		params[p.Value] = true
+	}
+
+	seen := make(map[string]bool)
+	ast.Inspect(node.Body, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Identifier)
+		if !ok || params[ident.Value] || seen[ident.Value] {
+			return true
+		}
+		seen[ident.Value] = true
+		entry, local := c.getLocalSymbol(ident.Value)
+		switch {
+		case !local:
+		case entry.Ptr.IsNil():
+			constants = append(constants, capture{name: ident.Value, entry: entry})
+		default:
+			captures = append(captures, capture{name: ident.Value, entry: entry})
+		}
+		return true
+	})
+	return captures, constants
+}
+
+// capturedNames lista os nomes usados dentro das funções anônimas de body. As variáveis locais
+// com esses nomes são alocadas no heap, para poderem ser compartilhadas com as closures; a lista
+// é feita só por nome, então uma variável homônima também vai para o heap, sem mudar o resultado.
+func capturedNames(body ast.Node) map[string]bool {
+	names := make(map[string]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		lit, ok := n.(*ast.FunctionLiteral)
+		if !ok {
+			return true
+		}
+		ast.Inspect(lit.Body, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Identifier); ok {
+				names[ident.Value] = true
+			}
+			return true
+		})
+		return false
+	})
+	return names
+}
+
+// allocLocal reserva o espaço de uma variável local: na pilha ou, quando alguma função anônima
+// usa o nome, no heap, para que a variável continue válida depois que a função atual retorna.
+func (c *CodeGenerator) allocLocal(name string, typ llvm.Type, label string) llvm.Value {
+	if !c.boxed[name] {
+		return c.builder.CreateAlloca(typ, label)
+	}
+	ptrType := llvm.PointerType(c.context.Int8Type(), 0)
+	mallocType := llvm.FunctionType(ptrType, []llvm.Type{c.context.Int64Type()}, false)
+	return c.builder.CreateCall(mallocType, c.mallocFunc, []llvm.Value{llvm.SizeOf(typ)}, label+"_box")
+}
+
+// getLocalSymbol procura um nome só nos escopos locais (acima do escopo global do pacote).
+func (c *CodeGenerator) getLocalSymbol(name string) (SymbolEntry, bool) {
+	for i := len(c.symbolTable) - 1; i >= 1; i-- {
+		if entry, ok := c.symbolTable[i][name]; ok {
+			return entry, true
+		}
+	}
+	return SymbolEntry{}, false
+}
+
+// genClosureCall chama o valor de closure denotado por node.Function, passando o ambiente como
+// primeiro argumento.
+func (c *CodeGenerator) genClosureCall(node *ast.CallExpression, sig closureSignature) llvm.Value {
+	closure := c.genExpression(node.Function)
+	fnPtr := c.builder.CreateExtractValue(closure, 0, "closure_fn")
+	env := c.builder.CreateExtractValue(closure, 1, "closure_env")
+
+	paramTypes := sig.fnType.ParamTypes()[1:]
+	if len(node.Arguments) != len(paramTypes) {
+		panic(errorAt(node, "%s espera %d argumentos, mas recebeu %d", node.Function.String(), len(paramTypes), len(node.Arguments)))
+	}
+	args := append([]llvm.Value{env}, c.genCallArguments(node.Arguments, paramTypes, sig.paramTypeNames)...)
+
+	name := "calltmp"
+	if sig.fnType.ReturnType().TypeKind() == llvm.VoidTypeKind {
+		name = ""
+	}
+	return c.builder.CreateCall(sig.fnType, fnPtr, args, name)
+}
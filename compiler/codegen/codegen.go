@@ -2,42 +2,13 @@ package codegen
 
 import (
 	"fmt"
-	"log"
-	"os"
 	"strings"
-	"sync"
 	"taquion/compiler/ast"
+	"taquion/compiler/trace"
 
 	"github.com/taquion-lang/go-llvm"
 )
 
-var (
-	logger   *log.Logger
-	logFile  *os.File
-	initOnce sync.Once
-)
-
-func initLogger() {
-	initOnce.Do(func() {
-		if err := os.MkdirAll("log", 0755); err != nil {
-			log.Fatalf("Erro ao criar diretório de log: %v", err)
-		}
-		var err error
-		logFile, err = os.OpenFile("log/codegen.log", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
-		if err != nil {
-			log.Fatalf("Erro ao abrir o arquivo de log codegen.log: %v", err)
-		}
-		logger = log.New(logFile, "CODEGEN: ", log.LstdFlags)
-		logger.Println("=== Nova sessão de log do codegen iniciada ===")
-	})
-}
-
-func CloseLogger() {
-	if logFile != nil {
-		logFile.Close()
-	}
-}
-
 type SymbolEntry struct {
 	Value     llvm.Value
 	Ptr       llvm.Value
@@ -47,6 +18,12 @@ type SymbolEntry struct {
 	IsLiteral bool
 }
 
+// loopContext guarda os blocos de destino de 'continue' e 'break' de um laço.
+type loopContext struct {
+	continueBlock llvm.BasicBlock // condição do 'while', pós-comando do 'for'
+	breakBlock    llvm.BasicBlock
+}
+
 type CodeGenerator struct {
 	module                    llvm.Module
 	builder                   llvm.Builder
@@ -54,6 +31,8 @@ type CodeGenerator struct {
 	symbolTable               []map[string]SymbolEntry
 	indentationLevel          int
 	currentFunctionReturnType llvm.Type
+	// currentFunctionReturnTypeName é o tipo de retorno declarado na fonte ("" para o 'main' implícito).
+	currentFunctionReturnTypeName string
 
 	printfFunc     llvm.Value
 	printfFuncType llvm.Type
@@ -61,39 +40,80 @@ type CodeGenerator struct {
 	strlenFunc     llvm.Value
 	strcpyFunc     llvm.Value
 	strcatFunc     llvm.Value
+	strcmpFunc     llvm.Value
+	snprintfFunc   llvm.Value
+	snprintfType   llvm.Type
+
+	// loops é a pilha dos laços em que o código atual está aninhado; o topo é o alvo de break/continue.
+	loops []loopContext
+
+	// pkg é o pacote em geração; packageScopes guarda o escopo global de cada pacote já gerado,
+	// indexado pelo caminho de importação, e imports mapeia os nomes importados no arquivo atual
+	// para esses caminhos.
+	pkg           *ast.Package
+	packageScopes map[string]map[string]SymbolEntry
+	imports       map[string]string
+
+	// initFunc é a função de inicialização do pacote atual, que calcula os globais com valor
+	// inicial não constante; initBlock é o bloco onde ela continua. initFuncs acumula as
+	// funções de todos os pacotes, chamadas no início do 'main'.
+	initFunc  llvm.Value
+	initBlock llvm.BasicBlock
+	initFuncs []llvm.Value
 
-	loopCondBlock llvm.BasicBlock
-	loopEndBlock  llvm.BasicBlock
+	// closureSignatures guarda a assinatura de cada tipo de função visto ("func(int) int");
+	// lambdaCount numera as funções anônimas geradas.
+	closureSignatures map[string]closureSignature
+	lambdaCount       int
+	// boxed lista os nomes usados por funções anônimas dentro da função atual: essas variáveis
+	// locais vivem no heap, compartilhadas com as closures (ver allocLocal).
+	boxed map[string]bool
+
+	// tupleElemNames guarda o nome do tipo de cada valor de um retorno múltiplo, indexado pelo
+	// nome do tipo ("(int, string)").
+	tupleElemNames map[string][]string
 
 	structTypes        map[string]llvm.Type
 	structFieldIndices map[string]map[string]int
+	// structFieldTypeNames guarda o nome do tipo (na fonte) de cada campo, para distinguir inteiros sem sinal.
+	structFieldTypeNames map[string]map[string]string
+
+	tracer trace.Tracer
 }
 
-func NewCodeGenerator() *CodeGenerator {
-	initLogger()
+// NewCodeGenerator cria um gerador de código LLVM. tracer pode ser nil, o que desliga o rastreamento.
+func NewCodeGenerator(tracer trace.Tracer) *CodeGenerator {
 	ctx := llvm.NewContext()
 	cg := &CodeGenerator{
 		context:          ctx,
 		module:           ctx.NewModule("main_module"),
 		builder:          ctx.NewBuilder(),
-		symbolTable:      []map[string]SymbolEntry{make(map[string]SymbolEntry)},
 		indentationLevel: 0,
+		tracer:           trace.OrNop(tracer),
 	}
 	cg.declareCFunctions()
+	cg.packageScopes = make(map[string]map[string]SymbolEntry)
 	cg.structTypes = make(map[string]llvm.Type)
 	cg.structFieldIndices = make(map[string]map[string]int)
-	logger.Println("Nova instância de CodeGenerator criada.")
+	cg.structFieldTypeNames = make(map[string]map[string]string)
+	cg.closureSignatures = make(map[string]closureSignature)
+	cg.tupleElemNames = make(map[string][]string)
+	cg.resetPackageState()
+	cg.logTracef("Nova instância de CodeGenerator criada.")
 	return cg
 }
 
-func (c *CodeGenerator) Close() {
-	CloseLogger()
+// Generate gera o módulo de um programa de um único arquivo.
+func (c *CodeGenerator) Generate(program *ast.Program) llvm.Module {
+	return c.GeneratePackages([]*ast.Package{{Name: "main", Files: []*ast.Program{program}}})
 }
 
-func (c *CodeGenerator) Generate(program *ast.Program) llvm.Module {
+// GeneratePackages gera num único módulo todos os pacotes de um programa, na ordem dada:
+// cada pacote deve vir depois dos pacotes que ele importa.
+func (c *CodeGenerator) GeneratePackages(pkgs []*ast.Package) llvm.Module {
 	defer c.trace("Generate")()
-	for _, stmt := range program.Statements {
-		c.genStatement(stmt)
+	for _, pkg := range pkgs {
+		c.genPackage(pkg)
 	}
 	mainFunc := c.module.NamedFunction("main")
 	if mainFunc.IsNil() {
@@ -105,9 +125,84 @@ func (c *CodeGenerator) Generate(program *ast.Program) llvm.Module {
 		tempBuilder.SetInsertPointAtEnd(entryBlock)
 		tempBuilder.CreateRet(llvm.ConstInt(c.context.Int32Type(), 0, false))
 	}
+	c.callInits(mainFunc)
 	return c.module
 }
 
+// genPackage gera os arquivos de um pacote. Os arquivos compartilham o escopo global do pacote,
+// mas cada um tem os seus próprios imports.
+func (c *CodeGenerator) genPackage(pkg *ast.Package) {
+	c.logTracef("Gerando pacote %s (%d arquivos)", pkg.Name, len(pkg.Files))
+	c.pkg = pkg
+	c.resetPackageState()
+	c.declarePackage(pkg)
+	for _, file := range pkg.Files {
+		c.imports = make(map[string]string)
+		for _, stmt := range file.Statements {
+			c.genStatement(stmt)
+		}
+	}
+	c.finishInit()
+	c.packageScopes[pkg.Path] = c.symbolTable[0]
+}
+
+// declarePackage registra os tipos e as assinaturas das funções de todos os arquivos do pacote
+// antes de gerar os corpos, para que um arquivo use o que outro declara, em qualquer ordem.
+// Os tipos são criados primeiro e só depois recebem os campos, que podem ser de outras structs.
+func (c *CodeGenerator) declarePackage(pkg *ast.Package) {
+	eachDecl := func(declare func(ast.Statement)) {
+		for _, file := range pkg.Files {
+			c.imports = make(map[string]string)
+			for _, stmt := range file.Statements {
+				if imp, ok := stmt.(*ast.ImportStatement); ok {
+					c.genImportStatement(imp)
+				}
+				declare(stmt)
+			}
+		}
+	}
+	eachDecl(func(stmt ast.Statement) {
+		if decl, ok := stmt.(*ast.TypeDeclaration); ok {
+			c.declareStructType(decl)
+		}
+	})
+	eachDecl(func(stmt ast.Statement) {
+		if decl, ok := stmt.(*ast.TypeDeclaration); ok {
+			c.ensureStructType(decl)
+		}
+	})
+	eachDecl(func(stmt ast.Statement) {
+		if decl, ok := stmt.(*ast.FunctionDeclaration); ok {
+			c.declareFunction(decl)
+		}
+	})
+}
+
+// resetPackageState limpa os símbolos do pacote anterior antes de gerar o próximo. As tabelas de
+// structs são compartilhadas: os nomes canônicos dos tipos já levam o prefixo do pacote.
+func (c *CodeGenerator) resetPackageState() {
+	c.symbolTable = []map[string]SymbolEntry{make(map[string]SymbolEntry)}
+	c.imports = make(map[string]string)
+}
+
+// mangle devolve o nome no módulo LLVM de um nome global do pacote atual. Os nomes do pacote
+// principal ficam como estão; os dos demais ganham o caminho do pacote como prefixo
+// (matematica.soma, util.texto.maiusculas), para que pacotes diferentes não colidam.
+func (c *CodeGenerator) mangle(name string) string {
+	if c.pkg == nil {
+		return name
+	}
+	return mangleIn(c.pkg.Path, name)
+}
+
+// mangleIn devolve o nome no módulo LLVM de um nome global do pacote com caminho importPath.
+func mangleIn(importPath, name string) string {
+	if importPath == "" {
+		return name
+	}
+	return strings.ReplaceAll(importPath, "/", ".") + "." + name
+}
+
 func (c *CodeGenerator) GetValueTypeSafe(val llvm.Value) llvm.Type {
 	if val.IsNil() {
 		return llvm.Type{}
@@ -127,14 +222,20 @@ func isBlockTerminated(block llvm.BasicBlock) bool {
 	}
 }
 
+// isUnreachableBlock informa se nenhum desvio leva ao bloco (ex: o 'merge' de um if/else
+// em que os dois ramos retornam).
+func isUnreachableBlock(block llvm.BasicBlock) bool {
+	return block != block.Parent().EntryBasicBlock() && block.AsValue().FirstUse().IsNil()
+}
+
 func (c *CodeGenerator) pushScope() {
-	c.logTrace("=> Entrando em novo escopo")
+	c.logTracef("=> Entrando em novo escopo")
 	c.symbolTable = append(c.symbolTable, make(map[string]SymbolEntry))
 }
 
 func (c *CodeGenerator) popScope() {
 	c.symbolTable = c.symbolTable[:len(c.symbolTable)-1]
-	c.logTrace("<= Saindo do escopo")
+	c.logTracef("<= Saindo do escopo")
 }
 
 func (c *CodeGenerator) setSymbol(name string, entry SymbolEntry) {
@@ -147,15 +248,15 @@ func (c *CodeGenerator) setSymbol(name string, entry SymbolEntry) {
 		typStr = entry.Typ.String()
 	}
 
-	c.logTrace(fmt.Sprintf(
+	c.logTracef(
 		"Definindo símbolo '%s' no escopo atual. IsLiteral: %t, Ptr: %v, Value: %v, Typ: %s, ArrayType: %s",
 		name, entry.IsLiteral, entry.Ptr, entry.Value, typStr, arrayTypeStr,
-	))
+	)
 	c.symbolTable[len(c.symbolTable)-1][name] = entry
 }
 
 func (c *CodeGenerator) getSymbol(name string) (SymbolEntry, bool) {
-	c.logTrace(fmt.Sprintf("Procurando símbolo '%s'", name))
+	c.logTracef("Procurando símbolo '%s'", name)
 	for i := len(c.symbolTable) - 1; i >= 0; i-- {
 		if entry, ok := c.symbolTable[i][name]; ok {
 			arrayTypeStr := "nil"
@@ -166,27 +267,77 @@ func (c *CodeGenerator) getSymbol(name string) (SymbolEntry, bool) {
 			if !entry.Typ.IsNil() {
 				typStr = entry.Typ.String()
 			}
-			c.logTrace(fmt.Sprintf(
+			c.logTracef(
 				"Símbolo '%s' encontrado no escopo %d. IsLiteral: %t, Ptr: %v, Value: %v, Typ: %s, ArrayType: %s",
 				name, i, entry.IsLiteral, entry.Ptr, entry.Value, typStr, arrayTypeStr,
-			))
+			)
 			return entry, true
 		}
 	}
-	c.logTrace(fmt.Sprintf("Símbolo '%s' não encontrado em nenhum escopo", name))
+	c.logTracef("Símbolo '%s' não encontrado em nenhum escopo", name)
 	return SymbolEntry{}, false
 }
 
-func (c *CodeGenerator) logTrace(msg string) {
+// getQualifiedSymbol resolve um nome qualificado pacote.nome para o símbolo global do pacote
+// importado. ok é false quando expr não é um nome qualificado (ex: o acesso a um campo de struct
+// ou uma variável local com o mesmo nome do pacote).
+func (c *CodeGenerator) getQualifiedSymbol(expr ast.Expression) (SymbolEntry, bool) {
+	member, ok := expr.(*ast.MemberExpression)
+	if !ok {
+		return SymbolEntry{}, false
+	}
+	pkgIdent, ok := member.Object.(*ast.Identifier)
+	if !ok {
+		return SymbolEntry{}, false
+	}
+	importPath, imported := c.imports[pkgIdent.Value]
+	if !imported {
+		return SymbolEntry{}, false
+	}
+	if _, shadowed := c.getSymbol(pkgIdent.Value); shadowed {
+		return SymbolEntry{}, false
+	}
+	entry, found := c.packageScopes[importPath][member.Property.Value]
+	if !found {
+		panic(errorAt(member.Property, "o pacote %s não declara '%s'", pkgIdent.Value, member.Property.Value))
+	}
+	return entry, true
+}
+
+// getCallee resolve o símbolo da função chamada: um nome qualificado de outro pacote ou um
+// nome do escopo atual.
+func (c *CodeGenerator) getCallee(expr ast.Expression) (SymbolEntry, bool) {
+	if entry, ok := c.getQualifiedSymbol(expr); ok {
+		return entry, true
+	}
+	return c.getSymbol(expr.String())
+}
+
+// errorAt formata uma mensagem de erro de geração de código prefixada pela posição do nó no código fonte.
+// Uso: panic(errorAt(node, "mensagem %s", arg))
+func errorAt(node ast.Node, format string, args ...interface{}) string {
+	msg := fmt.Sprintf(format, args...)
+	if node != nil {
+		msg = fmt.Sprintf("%s: %s", node.Pos(), msg)
+	}
+	return msg
+}
+
+// logTracef registra uma mensagem no trace do codegen, indentada pelo nível atual.
+// Com o rastreamento desligado, retorna antes de formatar qualquer coisa.
+func (c *CodeGenerator) logTracef(format string, args ...interface{}) {
+	if !c.tracer.Enabled(trace.Codegen) {
+		return
+	}
 	indent := strings.Repeat("    ", c.indentationLevel)
-	logger.Printf("%s%s\n", indent, msg)
+	c.tracer.Tracef(trace.Codegen, indent+format, args...)
 }
 
 func (c *CodeGenerator) trace(funcName string) func() {
-	c.logTrace(">> " + funcName)
+	c.logTracef(">> %s", funcName)
 	c.indentationLevel++
 	return func() {
 		c.indentationLevel--
-		c.logTrace("<< " + funcName)
+		c.logTracef("<< %s", funcName)
 	}
 }
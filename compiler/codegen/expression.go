@@ -14,8 +14,14 @@ func (c *CodeGenerator) genExpression(expr ast.Expression) llvm.Value {
 	switch node := expr.(type) {
 	case *ast.IntegerLiteral:
 		return c.genIntegerLiteral(node)
+	case *ast.FloatLiteral:
+		return c.genFloatLiteral(node)
 	case *ast.StringLiteral:
 		return c.genStringLiteral(node)
+	case *ast.InterpolatedString:
+		return c.genInterpolatedString(node)
+	case *ast.CharLiteral:
+		return c.genCharLiteral(node)
 	case *ast.BooleanLiteral:
 		return c.genBooleanLiteral(node)
 	case *ast.Identifier:
@@ -26,6 +32,8 @@ func (c *CodeGenerator) genExpression(expr ast.Expression) llvm.Value {
 		return c.genPrefixExpression(node)
 	case *ast.AssignmentExpression:
 		return c.genAssignmentExpression(node)
+	case *ast.PostfixExpression:
+		return c.genPostfixExpression(node)
 	case *ast.CallExpression:
 		return c.genCallExpression(node)
 	case *ast.IfExpression:
@@ -41,6 +49,6 @@ func (c *CodeGenerator) genExpression(expr ast.Expression) llvm.Value {
 	case *ast.CompositeLiteral:
 		return c.genCompositeLiteral(node)
 	default:
-		panic(fmt.Sprintf("Expressão não suportada: %T\n", node))
+		panic(errorAt(expr, "Expressão não suportada: %T", node))
 	}
 }
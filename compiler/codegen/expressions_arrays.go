@@ -8,65 +8,161 @@ import (
 )
 
 // genArrayLiteral gera código para um literal de array.
+// O tipo dos elementos vem do primeiro elemento (int para o literal vazio).
 func (c *CodeGenerator) genArrayLiteral(node *ast.ArrayLiteral) llvm.Value {
-	c.logTrace("Gerando ArrayLiteral")
-
-	elemCount := len(node.Elements)
-	elemType := c.context.Int32Type()
-	arrayType := llvm.ArrayType(elemType, elemCount)
+	return c.genArrayLiteralAs(node, llvm.Type{}, "")
+}
 
-	arrayPtr := c.builder.CreateAlloca(arrayType, "array_tmp")
-	c.logTrace(fmt.Sprintf("DEBUG: Alocando array na stack. Tipo: %v", arrayPtr.Type()))
+// genArrayLiteralAs gera um literal de array na pilha e devolve o ponteiro para ele.
+// Com um tipo esperado (ex: de uma anotação), os elementos são convertidos para o tipo
+// dos elementos e as posições não listadas ficam com o valor zero; com target nulo,
+// o tipo é inferido do literal.
+func (c *CodeGenerator) genArrayLiteralAs(node *ast.ArrayLiteral, target llvm.Type, targetName string) llvm.Value {
+	c.logTracef("Gerando ArrayLiteral")
+
+	var elemType llvm.Type
+	elemName := ""
+	length := len(node.Elements)
+	if !target.IsNil() {
+		elemType, elemName = target.ElementType(), elemTypeName(targetName)
+		if length > target.ArrayLength() {
+			panic(errorAt(node, "o literal tem %d elementos, mas o tipo %s comporta %d", length, targetName, target.ArrayLength()))
+		}
+		length = target.ArrayLength()
+	}
 
+	values := make([]llvm.Value, len(node.Elements))
 	for i, elemExpr := range node.Elements {
-		c.logTrace(fmt.Sprintf("DEBUG: Gerando elemento de array no índice %d", i))
-		elemValue := c.genExpression(elemExpr)
+		c.logTracef("DEBUG: Gerando elemento de array no índice %d", i)
+		val := c.genStoredValue(elemExpr, elemType, elemName)
+		if elemType.IsNil() {
+			elemType, elemName = c.GetValueTypeSafe(val), c.exprTypeName(elemExpr)
+		} else if converted, ok := c.coerceValue(val, elemExpr, elemType, elemName); ok {
+			val = converted
+		} else {
+			if elemName == "" {
+				elemName = elemType.String()
+			}
+			panic(errorAt(elemExpr, "elemento de array incompatível: esperado %s, recebido %s", elemName, c.describeType(elemExpr, val)))
+		}
+		values[i] = val
+	}
+	if elemType.IsNil() {
+		elemType = c.context.Int32Type()
+	}
 
+	arrayType := llvm.ArrayType(elemType, length)
+	arrayPtr := c.builder.CreateAlloca(arrayType, "array_tmp")
+	c.logTracef("DEBUG: Alocando array na stack. Tipo: %v", arrayType)
+	if len(values) < length {
+		c.builder.CreateStore(llvm.ConstNull(arrayType), arrayPtr)
+	}
+
+	for i, elemValue := range values {
 		indices := []llvm.Value{
 			llvm.ConstInt(c.context.Int32Type(), 0, false),
 			llvm.ConstInt(c.context.Int32Type(), uint64(i), false),
 		}
 		elemPtr := c.builder.CreateInBoundsGEP(arrayType, arrayPtr, indices, fmt.Sprintf("array_elem_%d_ptr", i))
-		c.logTrace(fmt.Sprintf("DEBUG: GEP para elemento %d. Endereço: %v", i, elemPtr))
-
 		c.builder.CreateStore(elemValue, elemPtr)
 	}
-	c.logTrace(fmt.Sprintf("DEBUG: Finalizando ArrayLiteral. Retornando ponteiro para o array: %v", arrayPtr))
+	c.logTracef("DEBUG: Finalizando ArrayLiteral. Retornando ponteiro para o array: %v", arrayPtr)
 	return arrayPtr
 }
 
-// genIndexExpression gera código para o acesso a um elemento de array.
-func (c *CodeGenerator) genIndexExpression(node *ast.IndexExpression) llvm.Value {
-	c.logTrace("Gerando IndexExpression")
-
-	arrayIdent, ok := node.Left.(*ast.Identifier)
-	if !ok {
-		panic("o lado esquerdo de uma expressão de índice deve ser um identificador")
+// genStoredValue gera o valor de uma expressão que será guardada em outro lugar (variável,
+// elemento de array, campo de struct, retorno). Variáveis e literais de array são manipulados
+// pelo ponteiro da pilha, então aqui o array é carregado e copiado por valor.
+func (c *CodeGenerator) genStoredValue(expr ast.Expression, target llvm.Type, targetName string) llvm.Value {
+	if lit, ok := expr.(*ast.ArrayLiteral); ok {
+		if !target.IsNil() && target.TypeKind() != llvm.ArrayTypeKind {
+			target = llvm.Type{}
+		}
+		ptr := c.genArrayLiteralAs(lit, target, targetName)
+		return c.builder.CreateLoad(ptr.AllocatedType(), ptr, "array_val")
 	}
-
-	arrayEntry, ok := c.getSymbol(arrayIdent.Value)
-	if !ok {
-		panic(fmt.Sprintf("array não declarado: %s", arrayIdent.Value))
+	val := c.genExpression(expr)
+	if ident, ok := expr.(*ast.Identifier); ok {
+		if entry, found := c.getSymbol(ident.Value); found && !entry.ArrayType.IsNil() {
+			return c.builder.CreateLoad(entry.ArrayType, val, "array_val")
+		}
 	}
+	return val
+}
+
+// genIndexExpression gera código para o acesso a um elemento de array (a[i], m[i][j], p.notas[i]).
+func (c *CodeGenerator) genIndexExpression(node *ast.IndexExpression) llvm.Value {
+	c.logTracef("Gerando IndexExpression")
 
-	if arrayEntry.ArrayType.IsNil() {
-		panic(fmt.Sprintf("a variável '%s' não é um array indexável", arrayIdent.Value))
+	if c.exprTypeName(node.Left) == "string" {
+		return c.genStringIndex(node)
 	}
+	elementPtr, elemType := c.genIndexAddress(node)
+	return c.builder.CreateLoad(elemType, elementPtr, "array_element_val")
+}
 
-	arrayPtr := c.genExpression(arrayIdent)
-	c.logTrace(fmt.Sprintf("DEBUG: Ponteiro para o array (valor da variável): %v", arrayPtr))
+// genIndexAddress calcula o endereço do elemento indexado e devolve também o seu tipo.
+func (c *CodeGenerator) genIndexAddress(node *ast.IndexExpression) (llvm.Value, llvm.Type) {
+	arrayPtr, arrayType := c.genArrayAddress(node.Left)
 
 	indexValue := c.genExpression(node.Index)
-	c.logTrace(fmt.Sprintf("DEBUG: Valor do índice: %v", indexValue))
+	if !isIntegerType(c.GetValueTypeSafe(indexValue)) {
+		panic(errorAt(node.Index, "o índice de um array deve ser um inteiro"))
+	}
+	if lit, ok := node.Index.(*ast.IntegerLiteral); ok && lit.Value >= uint64(arrayType.ArrayLength()) {
+		panic(errorAt(node.Index, "índice %d fora dos limites do array de %d elementos", lit.Value, arrayType.ArrayLength()))
+	}
+	c.logTracef("DEBUG: Valor do índice: %v", indexValue)
 
 	indices := []llvm.Value{
 		llvm.ConstInt(c.context.Int32Type(), 0, false),
 		indexValue,
 	}
+	elementPtr := c.builder.CreateInBoundsGEP(arrayType, arrayPtr, indices, "element_ptr")
+	return elementPtr, arrayType.ElementType()
+}
 
-	// CORREÇÃO: Usa o tipo de array explicitamente armazenado na tabela de símbolos.
-	elementPtr := c.builder.CreateInBoundsGEP(arrayEntry.ArrayType, arrayPtr, indices, "element_ptr")
-	c.logTrace(fmt.Sprintf("DEBUG: GEP para elemento do array. Endereço: %v", elementPtr))
+// genArrayAddress devolve o endereço e o tipo do array denotado por expr: uma variável,
+// um literal, um elemento de outro array (arrays multidimensionais) ou um campo de struct.
+func (c *CodeGenerator) genArrayAddress(expr ast.Expression) (llvm.Value, llvm.Type) {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		entry, ok := c.getSymbol(e.Value)
+		if !ok {
+			panic(errorAt(e, "array não declarado: %s", e.Value))
+		}
+		if entry.ArrayType.IsNil() {
+			panic(errorAt(e, "a variável '%s' não é um array indexável", e.Value))
+		}
+		// A variável guarda o ponteiro para o array na pilha.
+		return c.genExpression(e), entry.ArrayType
+	case *ast.IndexExpression:
+		ptr, typ := c.genIndexAddress(e)
+		if typ.TypeKind() != llvm.ArrayTypeKind {
+			panic(errorAt(e, "o elemento %s não é um array indexável", e.String()))
+		}
+		return ptr, typ
+	case *ast.ArrayLiteral:
+		ptr := c.genArrayLiteral(e)
+		return ptr, ptr.AllocatedType()
+	case *ast.MemberExpression:
+		if entry, ok := c.getQualifiedSymbol(e); ok && !entry.ArrayType.IsNil() {
+			return c.loadSymbol(entry, e.Property.Value), entry.ArrayType
+		}
+		ptr, typ := c.genMemberAddress(e)
+		if typ.TypeKind() != llvm.ArrayTypeKind {
+			panic(errorAt(e, "o campo '%s' não é um array indexável", e.Property.Value))
+		}
+		return ptr, typ
+	}
+	panic(errorAt(expr, "%s não é um array", expr.String()))
+}
 
-	return c.builder.CreateLoad(arrayEntry.ArrayType.ElementType(), elementPtr, "array_element_val")
+// genStringIndex gera s[i] para uma string: lê o i-ésimo byte e o devolve como caractere (i32).
+func (c *CodeGenerator) genStringIndex(node *ast.IndexExpression) llvm.Value {
+	str := c.genExpression(node.Left)
+	index := c.convertValue(c.genExpression(node.Index), c.exprTypeName(node.Index), c.context.Int64Type(), "int64", node.Index)
+	bytePtr := c.builder.CreateInBoundsGEP(c.context.Int8Type(), str, []llvm.Value{index}, "str_byte_ptr")
+	b := c.builder.CreateLoad(c.context.Int8Type(), bytePtr, "str_byte")
+	return c.builder.CreateZExt(b, c.context.Int32Type(), "str_char")
 }
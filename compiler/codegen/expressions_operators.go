@@ -1,7 +1,7 @@
 package codegen
 
 import (
-	"fmt"
+	"strings"
 	"taquion/compiler/ast"
 	"taquion/compiler/token"
 
@@ -10,120 +10,343 @@ import (
 
 // genInfixExpression gera o código para uma expressão infixa.
 func (c *CodeGenerator) genInfixExpression(node *ast.InfixExpression) llvm.Value {
-	c.logTrace(fmt.Sprintf("DEBUG: Gerando expressão infix: %s", node.Operator))
+	c.logTracef("DEBUG: Gerando expressão infix: %s", node.Operator)
+	if node.Operator == token.AND || node.Operator == token.OR {
+		return c.genLogicalExpression(node)
+	}
+
 	left := c.genExpression(node.Left)
 	right := c.genExpression(node.Right)
-	c.logTrace(fmt.Sprintf("DEBUG: Operandos da expressão infix: left=%v, right=%v", left, right))
+	c.logTracef("DEBUG: Operandos da expressão infix: left=%v, right=%v", left, right)
+	return c.genBinaryOperation(node, node.Operator, left, right, c.exprTypeName(node.Left), c.exprTypeName(node.Right))
+}
 
+// genBinaryOperation aplica um operador binário a dois valores já gerados.
+// É compartilhado entre expressões infixas e atribuições compostas (x += y).
+// leftName e rightName são os nomes dos tipos dos operandos na fonte: um operando sem sinal
+// seleciona as variantes sem sinal de divisão, resto, comparação e deslocamento à direita.
+func (c *CodeGenerator) genBinaryOperation(node ast.Node, operator string, left, right llvm.Value, leftName, rightName string) llvm.Value {
+	unsigned := isUnsignedTypeName(leftName) || isUnsignedTypeName(rightName)
 	isLeftString := c.GetValueTypeSafe(left).TypeKind() == llvm.PointerTypeKind
 	isRightString := c.GetValueTypeSafe(right).TypeKind() == llvm.PointerTypeKind
 
-	if node.Operator == token.PLUS && isLeftString && isRightString {
-		c.logTrace(fmt.Sprintf("DEBUG: Entrando em genStringConcat para '%s' + '%s'", node.Left.String(), node.Right.String()))
+	if operator == token.PLUS && isLeftString && isRightString {
+		c.logTracef("DEBUG: Entrando em genStringConcat")
 		return c.genStringConcat(left, right)
-	} else {
-		c.logTrace(fmt.Sprintf("DEBUG: Entrando no switch de operadores aritméticos para '%s'", node.Operator))
-		switch node.Operator {
-		case token.PLUS:
-			return c.builder.CreateAdd(left, right, "addtmp")
-		case token.MINUS:
-			return c.builder.CreateSub(left, right, "subtmp")
-		case token.ASTERISK:
-			return c.builder.CreateMul(left, right, "multmp")
-		case token.SLASH:
-			return c.builder.CreateSDiv(left, right, "divtmp")
-		case token.MODULO:
-			return c.builder.CreateSRem(left, right, "modtmp")
-		case token.EQ:
-			return c.builder.CreateICmp(llvm.IntEQ, left, right, "eqtmp")
-		case token.NOT_EQ:
-			return c.builder.CreateICmp(llvm.IntNE, left, right, "neqtmp")
-		case token.LT:
-			return c.builder.CreateICmp(llvm.IntSLT, left, right, "lttmp")
-		case token.GT:
-			return c.builder.CreateICmp(llvm.IntSGT, left, right, "gttmp")
-		default:
-			panic(fmt.Sprintf("operador infix não suportado: %s", node.Operator))
-		}
-	}
-}
-
-// genAssignmentExpression gera código para uma atribuição.
-func (c *CodeGenerator) genAssignmentExpression(node *ast.AssignmentExpression) llvm.Value {
-	c.logTrace("DEBUG: Gerando expressão de atribuição")
-	val := c.genExpression(node.Value)
+	}
 
-	if ident, ok := node.Left.(*ast.Identifier); ok {
-		c.logTrace(fmt.Sprintf("DEBUG: Atribuindo a um identificador: %s", ident.Value))
-		entry, ok := c.getSymbol(ident.Value)
-		if !ok {
-			panic(fmt.Sprintf("atribuição a variável não declarada: %s", ident.Value))
-		}
-		if entry.IsLiteral {
-			panic(fmt.Sprintf("atribuição a constante não é permitida: %s", ident.Value))
-		}
-		c.builder.CreateStore(val, entry.Ptr)
-		return val
+	left, right = c.unifyNumericOperands(node, left, right, leftName, rightName)
+	if isFloatType(c.GetValueTypeSafe(left)) {
+		return c.genFloatBinaryOperation(node, operator, left, right)
 	}
 
-	if indexExpr, ok := node.Left.(*ast.IndexExpression); ok {
-		c.logTrace("DEBUG: Atribuindo a um elemento de array")
-		arrayIdent, ok := indexExpr.Left.(*ast.Identifier)
-		if !ok {
-			panic("o lado esquerdo de uma expressão de índice deve ser um identificador")
+	c.logTracef("DEBUG: Entrando no switch de operadores aritméticos para '%s'", operator)
+	switch operator {
+	case token.PLUS:
+		return c.builder.CreateAdd(left, right, "addtmp")
+	case token.MINUS:
+		return c.builder.CreateSub(left, right, "subtmp")
+	case token.ASTERISK:
+		return c.builder.CreateMul(left, right, "multmp")
+	case token.SLASH:
+		if unsigned {
+			return c.builder.CreateUDiv(left, right, "udivtmp")
 		}
-		arrayEntry, ok := c.getSymbol(arrayIdent.Value)
-		if !ok {
-			panic(fmt.Sprintf("array não declarado: %s", arrayIdent.Value))
+		return c.builder.CreateSDiv(left, right, "divtmp")
+	case token.MODULO:
+		if unsigned {
+			return c.builder.CreateURem(left, right, "umodtmp")
+		}
+		return c.builder.CreateSRem(left, right, "modtmp")
+	case token.EQ:
+		return c.builder.CreateICmp(llvm.IntEQ, left, right, "eqtmp")
+	case token.NOT_EQ:
+		return c.builder.CreateICmp(llvm.IntNE, left, right, "neqtmp")
+	case token.LT:
+		return c.builder.CreateICmp(pickPredicate(unsigned, llvm.IntULT, llvm.IntSLT), left, right, "lttmp")
+	case token.GT:
+		return c.builder.CreateICmp(pickPredicate(unsigned, llvm.IntUGT, llvm.IntSGT), left, right, "gttmp")
+	case token.LT_EQ:
+		return c.builder.CreateICmp(pickPredicate(unsigned, llvm.IntULE, llvm.IntSLE), left, right, "letmp")
+	case token.GT_EQ:
+		return c.builder.CreateICmp(pickPredicate(unsigned, llvm.IntUGE, llvm.IntSGE), left, right, "getmp")
+	case token.BIT_AND:
+		return c.builder.CreateAnd(left, right, "andtmp")
+	case token.BIT_OR:
+		return c.builder.CreateOr(left, right, "ortmp")
+	case token.BIT_XOR:
+		return c.builder.CreateXor(left, right, "xortmp")
+	case token.SHL:
+		return c.builder.CreateShl(left, right, "shltmp")
+	case token.SHR:
+		if unsigned {
+			return c.builder.CreateLShr(left, right, "lshrtmp")
 		}
+		return c.builder.CreateAShr(left, right, "ashrtmp")
+	default:
+		panic(errorAt(node, "operador infix não suportado: %s", operator))
+	}
+}
+
+// pickPredicate escolhe o predicado de comparação inteira conforme o sinal dos operandos.
+func pickPredicate(unsigned bool, unsignedPred, signedPred llvm.IntPredicate) llvm.IntPredicate {
+	if unsigned {
+		return unsignedPred
+	}
+	return signedPred
+}
+
+// genFloatBinaryOperation gera o código de uma operação binária cujos operandos já são float/double.
+func (c *CodeGenerator) genFloatBinaryOperation(node ast.Node, operator string, left, right llvm.Value) llvm.Value {
+	c.logTracef("DEBUG: Entrando no switch de operadores de ponto flutuante para '%s'", operator)
+	switch operator {
+	case token.PLUS:
+		return c.builder.CreateFAdd(left, right, "faddtmp")
+	case token.MINUS:
+		return c.builder.CreateFSub(left, right, "fsubtmp")
+	case token.ASTERISK:
+		return c.builder.CreateFMul(left, right, "fmultmp")
+	case token.SLASH:
+		return c.builder.CreateFDiv(left, right, "fdivtmp")
+	case token.MODULO:
+		return c.builder.CreateFRem(left, right, "fmodtmp")
+	case token.EQ:
+		return c.builder.CreateFCmp(llvm.FloatOEQ, left, right, "feqtmp")
+	case token.NOT_EQ:
+		return c.builder.CreateFCmp(llvm.FloatUNE, left, right, "fneqtmp")
+	case token.LT:
+		return c.builder.CreateFCmp(llvm.FloatOLT, left, right, "flttmp")
+	case token.GT:
+		return c.builder.CreateFCmp(llvm.FloatOGT, left, right, "fgttmp")
+	case token.LT_EQ:
+		return c.builder.CreateFCmp(llvm.FloatOLE, left, right, "fletmp")
+	case token.GT_EQ:
+		return c.builder.CreateFCmp(llvm.FloatOGE, left, right, "fgetmp")
+	default:
+		panic(errorAt(node, "operador infix não suportado para float: %s", operator))
+	}
+}
 
-		if arrayEntry.ArrayType.IsNil() {
-			panic(fmt.Sprintf("a variável '%s' não é um array indexável", arrayIdent.Value))
+// genLogicalExpression gera && e || com avaliação em curto-circuito: o lado direito
+// só é avaliado em um bloco próprio quando o esquerdo não decide o resultado,
+// e os dois caminhos se juntam com um phi no bloco de merge.
+func (c *CodeGenerator) genLogicalExpression(node *ast.InfixExpression) llvm.Value {
+	isAnd := node.Operator == token.AND
+	prefix := "or"
+	if isAnd {
+		prefix = "and"
+	}
+
+	left := c.genBoolOperand(node.Left, node.Operator)
+	leftBlock := c.builder.GetInsertBlock()
+	function := leftBlock.Parent()
+	rhsBlock := c.context.AddBasicBlock(function, prefix+"_rhs")
+	mergeBlock := c.context.AddBasicBlock(function, prefix+"_merge")
+
+	if isAnd {
+		c.builder.CreateCondBr(left, rhsBlock, mergeBlock)
+	} else {
+		c.builder.CreateCondBr(left, mergeBlock, rhsBlock)
+	}
+
+	c.builder.SetInsertPointAtEnd(rhsBlock)
+	right := c.genBoolOperand(node.Right, node.Operator)
+	rhsEndBlock := c.builder.GetInsertBlock() // o lado direito pode ter criado novos blocos
+	c.builder.CreateBr(mergeBlock)
+
+	c.builder.SetInsertPointAtEnd(mergeBlock)
+	shortCircuit := llvm.ConstInt(c.context.Int1Type(), 0, false)
+	if !isAnd {
+		shortCircuit = llvm.ConstInt(c.context.Int1Type(), 1, false)
+	}
+	phi := c.builder.CreatePHI(c.context.Int1Type(), prefix+"tmp")
+	phi.AddIncoming([]llvm.Value{shortCircuit, right}, []llvm.BasicBlock{leftBlock, rhsEndBlock})
+	return phi
+}
+
+// genBoolOperand gera um operando de um operador lógico, exigindo que ele seja booleano (i1).
+func (c *CodeGenerator) genBoolOperand(expr ast.Expression, operator string) llvm.Value {
+	val := c.genExpression(expr)
+	typ := c.GetValueTypeSafe(val)
+	if !isIntegerType(typ) || typ.IntTypeWidth() != 1 {
+		panic(errorAt(expr, "operando de '%s' deve ser booleano, recebeu %v", operator, typ))
+	}
+	return val
+}
+
+// genAssignmentExpression gera código para uma atribuição simples ou composta (+=, -=, *=, /=, %=).
+func (c *CodeGenerator) genAssignmentExpression(node *ast.AssignmentExpression) llvm.Value {
+	c.logTracef("DEBUG: Gerando expressão de atribuição '%s'", node.Operator)
+	ptr, typ := c.genAssignTarget(node.Left)
+	var val llvm.Value
+	if typ.TypeKind() == llvm.ArrayTypeKind {
+		// Um elemento que é ele próprio um array (ex: m[0] = [1, 2]) recebe uma cópia do valor.
+		val = c.genStoredValue(node.Value, typ, c.exprTypeName(node.Left))
+		if c.GetValueTypeSafe(val) != typ {
+			panic(errorAt(node.Value, "não é possível atribuir %s a %s", c.describeType(node.Value, val), c.exprTypeName(node.Left)))
 		}
+	} else {
+		val = c.genExpression(node.Value)
+	}
+
+	if node.Operator != "" && node.Operator != token.ASSIGN {
+		operator := strings.TrimSuffix(node.Operator, "=")
+		current := c.builder.CreateLoad(typ, ptr, "compound_cur")
+		val = c.genBinaryOperation(node, operator, current, val, c.exprTypeName(node.Left), c.exprTypeName(node.Value))
+	}
+	// coerceValue também confere a assinatura quando o alvo é um valor de função.
+	leftName := c.exprTypeName(node.Left)
+	converted, ok := c.coerceValue(val, node.Value, typ, leftName)
+	if !ok {
+		panic(errorAt(node.Value, "não é possível atribuir %s a %s", c.describeType(node.Value, val), leftName))
+	}
+	val = converted
+
+	c.builder.CreateStore(val, ptr)
+	return val
+}
+
+// genPostfixExpression gera x++ e x--: incrementa/decrementa a variável e devolve o valor anterior.
+func (c *CodeGenerator) genPostfixExpression(node *ast.PostfixExpression) llvm.Value {
+	c.logTracef("DEBUG: Gerando expressão pós-fixa '%s'", node.Operator)
+	ptr, typ := c.genAssignTarget(node.Left)
+	old := c.builder.CreateLoad(typ, ptr, "postfix_old")
+
+	var updated llvm.Value
+	switch {
+	case isFloatType(typ) && node.Operator == token.INCREMENT:
+		updated = c.builder.CreateFAdd(old, llvm.ConstFloat(typ, 1), "postfix_inc")
+	case isFloatType(typ):
+		updated = c.builder.CreateFSub(old, llvm.ConstFloat(typ, 1), "postfix_dec")
+	case isIntegerType(typ) && typ.IntTypeWidth() > 1 && node.Operator == token.INCREMENT:
+		updated = c.builder.CreateAdd(old, llvm.ConstInt(typ, 1, false), "postfix_inc")
+	case isIntegerType(typ) && typ.IntTypeWidth() > 1:
+		updated = c.builder.CreateSub(old, llvm.ConstInt(typ, 1, false), "postfix_dec")
+	default:
+		panic(errorAt(node, "operador '%s' requer um operando numérico", node.Operator))
+	}
 
-		arrayPtr := c.genExpression(arrayIdent)
-		indexValue := c.genExpression(indexExpr.Index)
+	c.builder.CreateStore(updated, ptr)
+	return old
+}
 
-		indices := []llvm.Value{
-			llvm.ConstInt(c.context.Int32Type(), 0, false),
-			indexValue,
+// genAssignTarget resolve o lado esquerdo de uma atribuição, retornando o ponteiro
+// para a posição de memória e o tipo do valor armazenado nela.
+func (c *CodeGenerator) genAssignTarget(left ast.Expression) (llvm.Value, llvm.Type) {
+	if ident, ok := left.(*ast.Identifier); ok {
+		c.logTracef("DEBUG: Atribuindo a um identificador: %s", ident.Value)
+		entry, ok := c.getSymbol(ident.Value)
+		if !ok {
+			panic(errorAt(ident, "atribuição a variável não declarada: %s", ident.Value))
 		}
+		if entry.IsLiteral {
+			panic(errorAt(ident, "atribuição a constante não é permitida: %s", ident.Value))
+		}
+		return entry.Ptr, entry.Typ
+	}
 
-		elementPtr := c.builder.CreateInBoundsGEP(arrayEntry.ArrayType, arrayPtr, indices, "array_element_ptr")
-		c.builder.CreateStore(val, elementPtr)
-		return val
+	if indexExpr, ok := left.(*ast.IndexExpression); ok {
+		c.logTracef("DEBUG: Atribuindo a um elemento de array")
+		return c.genIndexAddress(indexExpr)
 	}
 
-	panic("o lado esquerdo de uma atribuição deve ser um identificador ou um índice de array")
+	panic(errorAt(left, "o lado esquerdo de uma atribuição deve ser um identificador ou um índice de array"))
 }
 
 // genCallExpression gera código para uma chamada de função.
 func (c *CodeGenerator) genCallExpression(node *ast.CallExpression) llvm.Value {
-	c.logTrace(fmt.Sprintf("DEBUG: Gerando chamada de função: %s", node.Function.String()))
+	c.logTracef("DEBUG: Gerando chamada de função: %s", node.Function.String())
 	if node.Function.String() == "print" {
 		return c.genPrintCall(node)
 	}
 
-	symbol, ok := c.getSymbol(node.Function.String())
+	symbol, ok := c.getCallee(node.Function)
+	if !ok || symbol.Typ.TypeKind() != llvm.FunctionTypeKind {
+		// Variáveis (e outras expressões) com valor de closure são chamadas pelo ponteiro.
+		if sig, isClosure := c.closureSignatures[c.exprTypeName(node.Function)]; isClosure {
+			return c.genClosureCall(node, sig)
+		}
+	}
 	if !ok {
-		panic(fmt.Sprintf("função não definida: %s", node.Function.String()))
+		// int(x), float(x), ... são conversões explícitas entre tipos numéricos.
+		if target, isType := c.primitiveType(node.Function.String()); isType && len(node.Arguments) == 1 {
+			if node.Function.String() == "string" && c.isCharExpr(node.Arguments[0]) {
+				return c.genCharToString(c.genExpression(node.Arguments[0]))
+			}
+			c.checkIntLiteralRange(node.Arguments[0], target, node.Function.String())
+			return c.convertValue(c.genExpression(node.Arguments[0]), c.exprTypeName(node.Arguments[0]), target, node.Function.String(), node)
+		}
+		panic(errorAt(node.Function, "função não definida: %s", node.Function.String()))
 	}
 
+	if symbol.Typ.TypeKind() != llvm.FunctionTypeKind {
+		panic(errorAt(node.Function, "%s não é uma função", node.Function.String()))
+	}
 	function := symbol.Value
 	functionType := symbol.Typ
+	if params := functionType.ParamTypes(); len(node.Arguments) != len(params) && !functionType.IsFunctionVarArg() {
+		panic(errorAt(node, "%s espera %d argumentos, mas recebeu %d", node.Function.String(), len(params), len(node.Arguments)))
+	}
+	args := c.genCallArguments(node.Arguments, functionType.ParamTypes(), c.closureSignatures[symbol.TypeName].paramTypeNames)
 
-	args := make([]llvm.Value, len(node.Arguments))
-	for i, argExpr := range node.Arguments {
-		args[i] = c.genExpression(argExpr)
+	// Chamadas a funções void não produzem valor e, no LLVM, não podem ter nome.
+	name := "calltmp"
+	if functionType.ReturnType().TypeKind() == llvm.VoidTypeKind {
+		name = ""
 	}
+	return c.builder.CreateCall(functionType, function, args, name)
+}
 
-	return c.builder.CreateCall(functionType, function, args, "calltmp")
+// genCallArguments gera os argumentos de uma chamada, convertendo-os para o tipo do parâmetro
+// (ex: 2 passado a um float) e rejeitando tipos incompatíveis. paramTypeNames são os nomes dos tipos dos
+// parâmetros na fonte, quando conhecidos; com eles, um valor de função precisa ter a mesma
+// assinatura do parâmetro.
+func (c *CodeGenerator) genCallArguments(exprs []ast.Expression, paramTypes []llvm.Type, paramTypeNames []string) []llvm.Value {
+	args := make([]llvm.Value, len(exprs))
+	for i, argExpr := range exprs {
+		name := ""
+		if i < len(paramTypeNames) {
+			name = paramTypeNames[i]
+		}
+		if i < len(paramTypes) && paramTypes[i].TypeKind() == llvm.ArrayTypeKind {
+			// Arrays são passados por valor: o argumento leva uma cópia do array.
+			args[i] = c.genStoredValue(argExpr, paramTypes[i], name)
+			continue
+		}
+		args[i] = c.genExpression(argExpr)
+		if i >= len(paramTypes) {
+			continue
+		}
+		converted, ok := c.coerceValue(args[i], argExpr, paramTypes[i], name)
+		if !ok {
+			expected := name
+			if expected == "" {
+				expected = paramTypes[i].String()
+			}
+			panic(errorAt(argExpr, "argumento %d incompatível: esperado %s, recebido %s", i+1, expected, c.describeType(argExpr, args[i])))
+		}
+		args[i] = converted
+	}
+	return args
 }
 
 // ... (resto do arquivo `expressions_operators.go` sem alterações) ...
 func (c *CodeGenerator) genIfExpression(node *ast.IfExpression) llvm.Value {
-	c.logTrace("DEBUG: Gerando expressão 'if'")
+	return c.genIf(node, true)
+}
+
+// genIf gera um 'if'. Usado como valor (asValue), cada ramo vale a sua última expressão e os
+// dois se juntam com um phi no bloco de merge; como instrução, esses valores são descartados.
+func (c *CodeGenerator) genIf(node *ast.IfExpression, asValue bool) llvm.Value {
+	c.logTracef("DEBUG: Gerando expressão 'if'")
+	if asValue && node.Alternative == nil {
+		panic(errorAt(node, "um 'if' usado como valor precisa de um 'else'"))
+	}
+
 	cond := c.genExpression(node.Condition)
+	if condType := c.GetValueTypeSafe(cond); !isIntegerType(condType) || condType.IntTypeWidth() != 1 {
+		panic(errorAt(node.Condition, "expressão condicional inválida no if, esperava i1, recebeu %v", condType))
+	}
 	function := c.builder.GetInsertBlock().Parent()
 	thenBlock := c.context.AddBasicBlock(function, "then")
 	elseBlock := c.context.AddBasicBlock(function, "else")
@@ -131,67 +354,137 @@ func (c *CodeGenerator) genIfExpression(node *ast.IfExpression) llvm.Value {
 
 	c.builder.CreateCondBr(cond, thenBlock, elseBlock)
 
+	var values []llvm.Value
+	var blocks []llvm.BasicBlock
+
 	c.builder.SetInsertPointAtEnd(thenBlock)
-	c.genStatement(node.Consequence)
+	thenVal, thenExpr := c.genIfBranch(node.Consequence, asValue)
 	if !isBlockTerminated(c.builder.GetInsertBlock()) {
+		if !thenVal.IsNil() {
+			values, blocks = append(values, thenVal), append(blocks, c.builder.GetInsertBlock())
+		}
 		c.builder.CreateBr(mergeBlock)
 	}
 
 	c.builder.SetInsertPointAtEnd(elseBlock)
 	if node.Alternative != nil {
-		c.genStatement(node.Alternative)
+		elseVal, elseExpr := c.genIfBranch(node.Alternative, asValue)
+		if !elseVal.IsNil() && !isBlockTerminated(c.builder.GetInsertBlock()) {
+			// Os dois ramos precisam ter o mesmo tipo; números são convertidos para o tipo do 'then'.
+			if len(values) > 0 {
+				converted, ok := c.coerceValue(elseVal, elseExpr, thenVal.Type(), c.exprTypeName(thenExpr))
+				if !ok {
+					panic(errorAt(elseExpr, "os ramos do 'if' têm tipos diferentes: %s e %s",
+						c.describeType(thenExpr, thenVal), c.describeType(elseExpr, elseVal)))
+				}
+				elseVal = converted
+			}
+			values, blocks = append(values, elseVal), append(blocks, c.builder.GetInsertBlock())
+		}
 	}
 	if !isBlockTerminated(c.builder.GetInsertBlock()) {
 		c.builder.CreateBr(mergeBlock)
 	}
 
 	c.builder.SetInsertPointAtEnd(mergeBlock)
-	return llvm.Value{}
+	if len(values) == 0 {
+		return llvm.Value{}
+	}
+	phi := c.builder.CreatePHI(values[0].Type(), "iftmp")
+	phi.AddIncoming(values, blocks)
+	return phi
+}
+
+// genIfBranch gera um ramo do 'if'. Como valor, o ramo vale a sua última instrução, que deve
+// ser uma expressão, a menos que o ramo termine antes (ex: com 'return' ou 'break').
+func (c *CodeGenerator) genIfBranch(block *ast.BlockStatement, asValue bool) (llvm.Value, ast.Expression) {
+	if !asValue {
+		c.genStatement(block)
+		return llvm.Value{}, nil
+	}
+
+	c.pushScope()
+	defer c.popScope()
+	for i, stmt := range block.Statements {
+		if isBlockTerminated(c.builder.GetInsertBlock()) {
+			return llvm.Value{}, nil
+		}
+		if exprStmt, ok := stmt.(*ast.ExpressionStatement); ok && i == len(block.Statements)-1 {
+			val := c.genExpression(exprStmt.Expression)
+			if val.IsNil() || val.Type().TypeKind() == llvm.VoidTypeKind {
+				panic(errorAt(exprStmt, "o ramo do 'if' usado como valor não produz valor"))
+			}
+			return val, exprStmt.Expression
+		}
+		c.genStatement(stmt)
+	}
+	if !isBlockTerminated(c.builder.GetInsertBlock()) {
+		panic(errorAt(block, "o bloco de um 'if' usado como valor deve terminar com uma expressão"))
+	}
+	return llvm.Value{}, nil
 }
 
 func (c *CodeGenerator) genPrefixExpression(node *ast.PrefixExpression) llvm.Value {
-	c.logTrace(fmt.Sprintf("DEBUG: Gerando expressão prefixo: %s", node.Operator))
+	c.logTracef("DEBUG: Gerando expressão prefixo: %s", node.Operator)
 	right := c.genExpression(node.Right)
 	switch node.Operator {
 	case "-":
+		if isFloatType(c.GetValueTypeSafe(right)) {
+			return c.builder.CreateFNeg(right, "fnegtmp")
+		}
 		return c.builder.CreateNeg(right, "negtmp")
 	case "!":
 		return c.builder.CreateNot(right, "nottmp")
+	case "~":
+		if !isIntegerType(c.GetValueTypeSafe(right)) {
+			panic(errorAt(node, "operador '~' requer um operando inteiro"))
+		}
+		return c.builder.CreateNot(right, "bitnottmp")
 	default:
-		panic(fmt.Sprintf("operador prefixo não suportado: %s", node.Operator))
+		panic(errorAt(node, "operador prefixo não suportado: %s", node.Operator))
 	}
 }
 
-func (c *CodeGenerator) genFunctionLiteral(node *ast.FunctionLiteral) llvm.Value {
-	return llvm.Value{}
-}
-
 func (c *CodeGenerator) genPrintCall(call *ast.CallExpression) llvm.Value {
-	c.logTrace("DEBUG: Gerando chamada para a função 'print'")
+	c.logTracef("DEBUG: Gerando chamada para a função 'print'")
+	if len(call.Arguments) == 0 {
+		panic(errorAt(call, "print espera ao menos um argumento"))
+	}
+	if len(call.Arguments) > 1 {
+		return c.genPrintfCall(call)
+	}
 	arg := c.genExpression(call.Arguments[0])
 	argType := c.GetValueTypeSafe(arg)
 	var format llvm.Value
 	finalArg := arg
 
 	if arg.IsNil() {
-		panic(fmt.Sprintf("argumento nulo para a função print: %v", call.Arguments[0]))
+		panic(errorAt(call.Arguments[0], "argumento nulo para a função print: %v", call.Arguments[0]))
 	}
 	if argType.IsNil() {
-		panic(fmt.Sprintf("tipo nulo para o argumento da função print: %v", arg))
+		panic(errorAt(call.Arguments[0], "tipo nulo para o argumento da função print: %v", arg))
 	}
 
 	switch argType.TypeKind() {
 	case llvm.IntegerTypeKind:
-		c.logTrace("DEBUG: Argumento de impressão é um inteiro.")
-		format = c.builder.CreateGlobalStringPtr("%d\n", "fmt_int")
-		if argType.IntTypeWidth() < 32 {
-			finalArg = c.builder.CreateSExt(arg, c.context.Int32Type(), "printf_arg_promo")
+		if c.isCharExpr(call.Arguments[0]) {
+			c.logTracef("DEBUG: Argumento de impressão é um caractere.")
+			format = c.builder.CreateGlobalStringPtr("%s\n", "fmt_char")
+			finalArg = c.genCharToString(arg)
+			break
 		}
+		c.logTracef("DEBUG: Argumento de impressão é um inteiro.")
+		format = c.builder.CreateGlobalStringPtr(intFormat(argType, c.isUnsignedExpr(call.Arguments[0]))+"\n", "fmt_int")
+		finalArg = c.printfArg(arg, call.Arguments[0])
+	case llvm.FloatTypeKind, llvm.DoubleTypeKind:
+		c.logTracef("DEBUG: Argumento de impressão é um número de ponto flutuante.")
+		format = c.builder.CreateGlobalStringPtr("%g\n", "fmt_float")
+		finalArg = c.printfArg(arg, call.Arguments[0])
 	case llvm.PointerTypeKind:
-		c.logTrace("DEBUG: Argumento de impressão é um ponteiro (string).")
+		c.logTracef("DEBUG: Argumento de impressão é um ponteiro (string).")
 		format = c.builder.CreateGlobalStringPtr("%s\n", "fmt_str")
 	default:
-		c.logTrace("DEBUG: Argumento de impressão é um tipo desconhecido, tentando conversão para string.")
+		c.logTracef("DEBUG: Argumento de impressão é um tipo desconhecido, tentando conversão para string.")
 		i8PtrType := llvm.PointerType(c.context.Int8Type(), 0)
 		finalArg = c.builder.CreatePointerCast(arg, i8PtrType, "printf_arg_forced_cast")
 		format = c.builder.CreateGlobalStringPtr("%s\n", "fmt_str")
@@ -201,8 +494,60 @@ func (c *CodeGenerator) genPrintCall(call *ast.CallExpression) llvm.Value {
 	return c.builder.CreateCall(printfFuncType, c.printfFunc, []llvm.Value{format, finalArg}, "printf_call")
 }
 
+// genPrintfCall trata print("formato", args...): o primeiro argumento é um formato no estilo
+// printf (%d, %f, %g, %s, ...) e uma quebra de linha é acrescentada ao final.
+func (c *CodeGenerator) genPrintfCall(call *ast.CallExpression) llvm.Value {
+	var format llvm.Value
+	if lit, ok := call.Arguments[0].(*ast.StringLiteral); ok {
+		format = c.builder.CreateGlobalStringPtr(lit.Value+"\n", "fmt_user")
+	} else {
+		userFormat := c.genExpression(call.Arguments[0])
+		if c.GetValueTypeSafe(userFormat).TypeKind() != llvm.PointerTypeKind {
+			panic(errorAt(call.Arguments[0], "o primeiro argumento de print com vários argumentos deve ser uma string de formato"))
+		}
+		format = c.genStringConcat(userFormat, c.builder.CreateGlobalStringPtr("\n", "fmt_newline"))
+	}
+
+	args := []llvm.Value{format}
+	for _, argExpr := range call.Arguments[1:] {
+		args = append(args, c.printfArg(c.genExpression(argExpr), argExpr))
+	}
+	return c.builder.CreateCall(c.printfFuncType, c.printfFunc, args, "printf_call")
+}
+
+// intFormat escolhe o especificador do printf para um inteiro conforme largura e sinal.
+func intFormat(t llvm.Type, unsigned bool) string {
+	switch {
+	case t.IntTypeWidth() > 32 && unsigned:
+		return "%llu"
+	case t.IntTypeWidth() > 32:
+		return "%lld"
+	case unsigned:
+		return "%u"
+	default:
+		return "%d"
+	}
+}
+
+// printfArg aplica as promoções de argumentos variádicos do C: inteiros menores que 32 bits
+// viram i32 e float vira double.
+func (c *CodeGenerator) printfArg(val llvm.Value, node ast.Node) llvm.Value {
+	typ := c.GetValueTypeSafe(val)
+	switch {
+	case isIntegerType(typ) && typ.IntTypeWidth() < 32:
+		if expr, ok := node.(ast.Expression); ok && c.isUnsignedExpr(expr) {
+			return c.builder.CreateZExt(val, c.context.Int32Type(), "printf_arg_zext")
+		}
+		return c.convertValue(val, "", c.context.Int32Type(), "", node)
+	case isFloatType(typ) && floatBits(typ) < 64:
+		return c.convertValue(val, "", c.context.DoubleType(), "", node)
+	default:
+		return val
+	}
+}
+
 func (c *CodeGenerator) genStringConcat(left, right llvm.Value) llvm.Value {
-	c.logTrace("Gerando concatenação de strings")
+	c.logTracef("Gerando concatenação de strings")
 
 	i8PtrType := llvm.PointerType(c.context.Int8Type(), 0)
 	sizeType := c.context.Int64Type()
@@ -214,7 +559,7 @@ func (c *CodeGenerator) genStringConcat(left, right llvm.Value) llvm.Value {
 
 	finalLeft := c.builder.CreatePointerCast(left, i8PtrType, "str_concat_left_cast")
 	finalRight := c.builder.CreatePointerCast(right, i8PtrType, "str_concat_right_cast")
-	c.logTrace(fmt.Sprintf("DEBUG: Argumentos de concatenação: finalLeft=%v, finalRight=%v", finalLeft, finalRight))
+	c.logTracef("DEBUG: Argumentos de concatenação: finalLeft=%v, finalRight=%v", finalLeft, finalRight)
 
 	len1 := c.builder.CreateCall(strlenType, c.strlenFunc, []llvm.Value{finalLeft}, "len1")
 	len2 := c.builder.CreateCall(strlenType, c.strlenFunc, []llvm.Value{finalRight}, "len2")
@@ -225,6 +570,6 @@ func (c *CodeGenerator) genStringConcat(left, right llvm.Value) llvm.Value {
 	c.builder.CreateCall(strcpyType, c.strcpyFunc, []llvm.Value{newBuffer, finalLeft}, "")
 	c.builder.CreateCall(strcatType, c.strcatFunc, []llvm.Value{newBuffer, finalRight}, "")
 
-	c.logTrace(fmt.Sprintf("DEBUG: Concatenação completa. Retornando novo buffer: %v", newBuffer))
+	c.logTracef("DEBUG: Concatenação completa. Retornando novo buffer: %v", newBuffer)
 	return newBuffer
 }
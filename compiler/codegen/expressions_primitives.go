@@ -1,8 +1,8 @@
 package codegen
 
 import (
-	"fmt"
-	"strconv"
+	"math"
+	"strings"
 	"taquion/compiler/ast"
 
 	"github.com/taquion-lang/go-llvm"
@@ -10,23 +10,133 @@ import (
 
 // genIntegerLiteral gera um literal inteiro.
 func (c *CodeGenerator) genIntegerLiteral(node *ast.IntegerLiteral) llvm.Value {
-	val, _ := strconv.ParseInt(node.TokenLiteral(), 10, 64)
-	c.logTrace(fmt.Sprintf("DEBUG: Gerando literal inteiro: %d", val))
-	return llvm.ConstInt(c.context.Int32Type(), uint64(val), false)
+	val := node.Value
+	c.logTracef("DEBUG: Gerando literal inteiro: %d", val)
+	// Literais que não cabem em 32 bits são promovidos a i64.
+	if val > math.MaxInt32 {
+		return llvm.ConstInt(c.context.Int64Type(), val, false)
+	}
+	return llvm.ConstInt(c.context.Int32Type(), val, false)
+}
+
+// genFloatLiteral gera um literal de ponto flutuante (double).
+func (c *CodeGenerator) genFloatLiteral(node *ast.FloatLiteral) llvm.Value {
+	c.logTracef("DEBUG: Gerando literal float: %g", node.Value)
+	return llvm.ConstFloat(c.context.DoubleType(), node.Value)
 }
 
 // genStringLiteral gera um literal de string.
 func (c *CodeGenerator) genStringLiteral(node *ast.StringLiteral) llvm.Value {
 	i8PtrType := llvm.PointerType(c.context.Int8Type(), 0)
-	c.logTrace(fmt.Sprintf("DEBUG: Gerando StringLiteral para: '%s'", node.Value))
+	c.logTracef("DEBUG: Gerando StringLiteral para: '%s'", node.Value)
 	globalStringPtr := c.builder.CreateGlobalStringPtr(node.Value, "str_literal")
-	c.logTrace(fmt.Sprintf("DEBUG: GlobalStringPtr criado. Valor: %v, Tipo: %v", globalStringPtr, c.GetValueTypeSafe(globalStringPtr)))
+	c.logTracef("DEBUG: GlobalStringPtr criado. Valor: %v, Tipo: %v", globalStringPtr, c.GetValueTypeSafe(globalStringPtr))
 	return c.builder.CreatePointerCast(globalStringPtr, i8PtrType, "str_literal_to_i8ptr")
 }
 
+// genInterpolatedString monta um formato no estilo printf com os trechos de texto e um
+// especificador por expressão embutida, e usa snprintf para produzir uma nova string no heap.
+func (c *CodeGenerator) genInterpolatedString(node *ast.InterpolatedString) llvm.Value {
+	c.logTracef("DEBUG: Gerando string interpolada: %s", node.String())
+	var format strings.Builder
+	var args []llvm.Value
+
+	for _, part := range node.Parts {
+		if lit, ok := part.(*ast.StringLiteral); ok {
+			format.WriteString(strings.ReplaceAll(lit.Value, "%", "%%"))
+			continue
+		}
+		val := c.genExpression(part)
+		typ := c.GetValueTypeSafe(val)
+		switch {
+		case isIntegerType(typ) && c.isCharExpr(part):
+			format.WriteString("%s")
+			args = append(args, c.genCharToString(val))
+		case isIntegerType(typ) && typ.IntTypeWidth() == 1:
+			format.WriteString("%s")
+			trueStr := c.builder.CreateGlobalStringPtr("true", "interp_true")
+			falseStr := c.builder.CreateGlobalStringPtr("false", "interp_false")
+			args = append(args, c.builder.CreateSelect(val, trueStr, falseStr, "interp_bool"))
+		case isIntegerType(typ):
+			format.WriteString(intFormat(typ, c.isUnsignedExpr(part)))
+			args = append(args, c.printfArg(val, part))
+		case isFloatType(typ):
+			format.WriteString("%g")
+			args = append(args, c.printfArg(val, part))
+		case !typ.IsNil() && typ.TypeKind() == llvm.PointerTypeKind:
+			format.WriteString("%s")
+			args = append(args, val)
+		default:
+			panic(errorAt(part, "não é possível interpolar um valor do tipo %v", typ))
+		}
+	}
+
+	i8PtrType := llvm.PointerType(c.context.Int8Type(), 0)
+	fmtPtr := c.builder.CreateGlobalStringPtr(format.String(), "interp_fmt")
+
+	// Primeira chamada só mede o tamanho do resultado; a segunda escreve no buffer alocado.
+	measureArgs := append([]llvm.Value{llvm.ConstNull(i8PtrType), llvm.ConstInt(c.context.Int64Type(), 0, false), fmtPtr}, args...)
+	length := c.builder.CreateCall(c.snprintfType, c.snprintfFunc, measureArgs, "interp_len")
+	size := c.builder.CreateAdd(c.builder.CreateSExt(length, c.context.Int64Type(), "interp_len64"), llvm.ConstInt(c.context.Int64Type(), 1, false), "interp_size")
+
+	mallocType := llvm.FunctionType(i8PtrType, []llvm.Type{c.context.Int64Type()}, false)
+	buffer := c.builder.CreateCall(mallocType, c.mallocFunc, []llvm.Value{size}, "interp_buf")
+	writeArgs := append([]llvm.Value{buffer, size, fmtPtr}, args...)
+	c.builder.CreateCall(c.snprintfType, c.snprintfFunc, writeArgs, "")
+	return buffer
+}
+
+// genCharLiteral gera um literal de caractere como seu code point em i32.
+func (c *CodeGenerator) genCharLiteral(node *ast.CharLiteral) llvm.Value {
+	c.logTracef("DEBUG: Gerando literal de caractere: %s", node.String())
+	return llvm.ConstInt(c.context.Int32Type(), uint64(node.Value), false)
+}
+
+// genCharToString codifica o code point em val como UTF-8 numa nova string no heap.
+// Os bytes de cada comprimento possível são calculados sem desvios e escolhidos com select;
+// os bytes não usados ficam zerados e terminam a string.
+func (c *CodeGenerator) genCharToString(val llvm.Value) llvm.Value {
+	i8, i32, i64 := c.context.Int8Type(), c.context.Int32Type(), c.context.Int64Type()
+	val = c.convertValue(val, "char", i32, "char", nil)
+	k := func(v uint64) llvm.Value { return llvm.ConstInt(i32, v, false) }
+	// cont devolve o byte de continuação 10xxxxxx com os bits de val a partir de shift.
+	cont := func(shift uint64) llvm.Value {
+		bits := c.builder.CreateAnd(c.builder.CreateLShr(val, k(shift), ""), k(0x3F), "")
+		return c.builder.CreateOr(bits, k(0x80), "")
+	}
+	lead := func(shift, mark uint64) llvm.Value {
+		return c.builder.CreateOr(c.builder.CreateLShr(val, k(shift), ""), k(mark), "")
+	}
+
+	is1 := c.builder.CreateICmp(llvm.IntULT, val, k(0x80), "utf8_1")
+	is2 := c.builder.CreateICmp(llvm.IntULT, val, k(0x800), "utf8_2")
+	is3 := c.builder.CreateICmp(llvm.IntULT, val, k(0x10000), "utf8_3")
+	choose := func(v1, v2, v3, v4 llvm.Value) llvm.Value {
+		v := c.builder.CreateSelect(is3, v3, v4, "")
+		v = c.builder.CreateSelect(is2, v2, v, "")
+		return c.builder.CreateTrunc(c.builder.CreateSelect(is1, v1, v, ""), i8, "utf8_byte")
+	}
+	bytes := []llvm.Value{
+		choose(val, lead(6, 0xC0), lead(12, 0xE0), lead(18, 0xF0)),
+		choose(k(0), cont(0), cont(6), cont(12)),
+		choose(k(0), k(0), cont(0), cont(6)),
+		choose(k(0), k(0), k(0), cont(0)),
+		llvm.ConstInt(i8, 0, false),
+	}
+
+	i8PtrType := llvm.PointerType(i8, 0)
+	mallocType := llvm.FunctionType(i8PtrType, []llvm.Type{i64}, false)
+	buffer := c.builder.CreateCall(mallocType, c.mallocFunc, []llvm.Value{llvm.ConstInt(i64, uint64(len(bytes)), false)}, "char_str")
+	for i, b := range bytes {
+		ptr := c.builder.CreateInBoundsGEP(i8, buffer, []llvm.Value{llvm.ConstInt(i64, uint64(i), false)}, "char_str_byte")
+		c.builder.CreateStore(b, ptr)
+	}
+	return buffer
+}
+
 // genBooleanLiteral gera um literal booleano.
 func (c *CodeGenerator) genBooleanLiteral(node *ast.BooleanLiteral) llvm.Value {
-	c.logTrace(fmt.Sprintf("DEBUG: Gerando literal booleano: %v", node.Value))
+	c.logTracef("DEBUG: Gerando literal booleano: %v", node.Value)
 	if node.Value {
 		return llvm.ConstInt(c.context.Int1Type(), 1, false)
 	}
@@ -37,20 +147,28 @@ func (c *CodeGenerator) genBooleanLiteral(node *ast.BooleanLiteral) llvm.Value {
 func (c *CodeGenerator) genIdentifier(node *ast.Identifier) llvm.Value {
 	entry, ok := c.getSymbol(node.Value)
 	if !ok {
-		panic(fmt.Sprintf("variável não definida: %s", node.Value))
+		panic(errorAt(node, "variável não definida: %s", node.Value))
 	}
-	c.logTrace(fmt.Sprintf("DEBUG: Símbolo '%s' encontrado. IsLiteral: %t, Ptr: %v, Value: %v, Typ: %v", node.Value, entry.IsLiteral, entry.Ptr, entry.Value, entry.Typ))
+	c.logTracef("DEBUG: Símbolo '%s' encontrado. IsLiteral: %t, Ptr: %v, Value: %v, Typ: %v", node.Value, entry.IsLiteral, entry.Ptr, entry.Value, entry.Typ)
+	return c.loadSymbol(entry, node.Value)
+}
 
+// loadSymbol devolve o valor atual de um símbolo: o próprio valor de constantes, a closure
+// de uma função declarada, ou o valor carregado da memória de variáveis (locais ou globais).
+func (c *CodeGenerator) loadSymbol(entry SymbolEntry, name string) llvm.Value {
+	if entry.Typ.TypeKind() == llvm.FunctionTypeKind {
+		return c.functionValue(entry)
+	}
 	if entry.IsLiteral {
-		c.logTrace(fmt.Sprintf("DEBUG: Símbolo '%s' é um literal/função. Retornando valor: %v", node.Value, entry.Value))
+		c.logTracef("DEBUG: Símbolo '%s' é um literal/função. Retornando valor: %v", name, entry.Value)
 		if !entry.Ptr.IsNil() {
-			c.logTrace(fmt.Sprintf("DEBUG: Símbolo literal é um ponteiro. Carregando valor. Tipo do ponteiro: %v, Tipo do valor: %v", c.GetValueTypeSafe(entry.Ptr), entry.Typ))
-			return c.builder.CreateLoad(entry.Typ, entry.Ptr, node.Value)
+			c.logTracef("DEBUG: Símbolo literal é um ponteiro. Carregando valor. Tipo do ponteiro: %v, Tipo do valor: %v", c.GetValueTypeSafe(entry.Ptr), entry.Typ)
+			return c.builder.CreateLoad(entry.Typ, entry.Ptr, name)
 		}
 		return entry.Value
 	}
 
-	c.logTrace(fmt.Sprintf("DEBUG: Símbolo '%s' é uma variável. Carregando do ponteiro: %v. Tipo do valor: %v", node.Value, entry.Ptr, entry.Typ))
-	loadedValue := c.builder.CreateLoad(entry.Typ, entry.Ptr, node.Value)
+	c.logTracef("DEBUG: Símbolo '%s' é uma variável. Carregando do ponteiro: %v. Tipo do valor: %v", name, entry.Ptr, entry.Typ)
+	loadedValue := c.builder.CreateLoad(entry.Typ, entry.Ptr, name)
 	return loadedValue
 }
@@ -1,7 +1,6 @@
 package codegen
 
 import (
-	"fmt"
 	"taquion/compiler/ast"
 
 	"github.com/taquion-lang/go-llvm"
@@ -9,57 +8,118 @@ import (
 
 // genFunctionDeclaration gera código para a declaração de funções.
 func (c *CodeGenerator) genFunctionDeclaration(node *ast.FunctionDeclaration) {
-	var retType llvm.Type
-	if node.Name.Value == "main" {
-		retType = c.context.Int32Type()
-	} else {
-		// For now, let's assume other functions also return i32.
-		// This might need to be determined from the AST later.
-		retType = c.context.Int32Type()
+	function, paramTypeNames := c.declareFunction(node)
+	c.currentFunctionReturnType, c.currentFunctionReturnTypeName = c.functionReturnType(node)
+
+	if node.Body != nil {
+		if function.BasicBlocksCount() > 0 {
+			panic(errorAt(node.Name, "a função '%s' já foi declarada neste pacote", node.Name.Value))
+		}
+		entryBlock := c.context.AddBasicBlock(function, "entry")
+		c.builder.SetInsertPointAtEnd(entryBlock)
+		c.pushScope()
+		savedBoxed := c.boxed
+		c.boxed = capturedNames(node.Body)
+		defer func() { c.boxed = savedBoxed }()
+
+		for i, param := range node.Parameters {
+			paramValue := function.Param(i)
+			paramValue.SetName(param.Value)
+			// Parâmetros são variáveis locais como as do 'let', inclusive arrays (recebidos por
+			// valor e guardados numa alocação própria).
+			c.defineLocal(param, paramValue, paramTypeNames[i], false)
+		}
+
+		c.genStatement(node.Body)
+		c.popScope()
+		c.genFunctionEnd(node.Name, "a função '"+node.Name.Value+"'")
 	}
-	c.currentFunctionReturnType = retType
+}
+
+// genFunctionEnd fecha o último bloco de uma função que cai no fim sem 'return'. what descreve
+// a função nas mensagens de erro.
+func (c *CodeGenerator) genFunctionEnd(node ast.Node, what string) {
+	block := c.builder.GetInsertBlock()
+	if isBlockTerminated(block) {
+		return
+	}
+	retType := c.currentFunctionReturnType
+	switch {
+	case retType.TypeKind() == llvm.VoidTypeKind:
+		c.builder.CreateRetVoid()
+	case c.currentFunctionReturnTypeName == "":
+		// 'main' sem tipo declarado devolve 0 ao cair no fim.
+		c.builder.CreateRet(llvm.ConstInt(retType, 0, false))
+	case isUnreachableBlock(block):
+		c.builder.CreateUnreachable()
+	default:
+		panic(errorAt(node, "%s termina sem devolver um valor do tipo %s", what, c.currentFunctionReturnTypeName))
+	}
+}
+
+// declareFunction cria o protótipo da função no módulo (ou reaproveita o já criado por
+// declarePackage) e registra o seu símbolo. Devolve a função e os nomes dos tipos dos parâmetros.
+func (c *CodeGenerator) declareFunction(node *ast.FunctionDeclaration) (llvm.Value, []string) {
+	retType, retTypeName := c.functionReturnType(node)
 
 	// ▼▼▼ START OF FIX ▼▼▼
 	// Determine parameter types from the AST, not by hardcoding them.
 	paramTypes := make([]llvm.Type, len(node.Parameters))
+	paramTypeNames := make([]string, len(node.Parameters))
 	for i, p := range node.Parameters {
 		// This relies on the AST having the correct type information for each parameter.
 		// Your ast.Identifier has a 'Type' field which should be an ast.Identifier itself (e.g., Value: "string").
 		if p.Type == nil {
-			panic(fmt.Sprintf("o parâmetro '%s' na função '%s' não possui um tipo definido na AST", p.Value, node.Name.Value))
+			panic(errorAt(p, "o parâmetro '%s' na função '%s' não possui um tipo definido na AST", p.Value, node.Name.Value))
 		}
 		// Use the existing type lookup utility.
 		paramTypes[i] = c.lookupLLVMType(p.Type)
+		paramTypeNames[i] = c.typeName(p.Type)
 	}
 	// ▲▲▲ END OF FIX ▲▲▲
 
 	funcType := llvm.FunctionType(retType, paramTypes, false)
-	// The rest of the function remains the same...
-	function := llvm.AddFunction(c.module, node.Name.Value, funcType)
-	c.setSymbol(node.Name.Value, SymbolEntry{Value: function, Typ: funcType, IsLiteral: true})
-
-	if node.Body != nil {
-		entryBlock := c.context.AddBasicBlock(function, "entry")
-		c.builder.SetInsertPointAtEnd(entryBlock)
-		c.pushScope()
+	var function llvm.Value
+	if entry, declared := c.symbolTable[0][node.Name.Value]; declared && entry.Typ.TypeKind() == llvm.FunctionTypeKind {
+		function = entry.Value
+	} else {
+		function = llvm.AddFunction(c.module, c.mangle(node.Name.Value), funcType)
+	}
+	// TypeName guarda o tipo da função ("func(int) int"), cuja assinatura dá o tipo de retorno
+	// das chamadas em exprTypeName.
+	typeName := funcTypeName(paramTypeNames, retTypeName)
+	if retTypeName != "" || retType.TypeKind() == llvm.VoidTypeKind {
+		// O 'main' implícito devolve i32 sem um tipo na fonte e não define um tipo de função.
+		c.registerFuncType(typeName, retType, paramTypes, paramTypeNames, retTypeName)
+	}
+	c.setSymbol(node.Name.Value, SymbolEntry{Value: function, Typ: funcType, TypeName: typeName, IsLiteral: true})
+	return function, paramTypeNames
+}
 
-		for i, param := range node.Parameters {
-			paramValue := function.Param(i)
-			paramValue.SetName(param.Value)
-			// The alloca should also use the correct type.
-			alloca := c.builder.CreateAlloca(paramTypes[i], param.Value)
-			c.builder.CreateStore(paramValue, alloca)
-			c.setSymbol(param.Value, SymbolEntry{Ptr: alloca, Typ: paramTypes[i], IsLiteral: false})
-			c.setSymbol(param.Value, SymbolEntry{Ptr: alloca, Typ: paramTypes[i], TypeName: param.Type.Value, IsLiteral: false})
+// functionReturnType resolve o tipo de retorno declarado da função e o nome desse tipo na fonte.
+// Sem tipo declarado a função é void, exceto 'main', que devolve o código de saída (i32) ao C.
+func (c *CodeGenerator) functionReturnType(node *ast.FunctionDeclaration) (llvm.Type, string) {
+	if node.ReturnType == nil {
+		if node.Name.Value == "main" {
+			return c.context.Int32Type(), ""
 		}
+		return c.context.VoidType(), ""
+	}
+	retType := c.lookupLLVMType(node.ReturnType)
+	if node.Name.Value == "main" && !isIntegerType(retType) {
+		panic(errorAt(node.ReturnType, "a função 'main' deve devolver um inteiro, não %s", node.ReturnType.String()))
+	}
+	return retType, c.typeName(node.ReturnType)
+}
 
-		c.genStatement(node.Body)
-		c.popScope()
-
-		if !isBlockTerminated(c.builder.GetInsertBlock()) {
-			if retType.TypeKind() == llvm.IntegerTypeKind {
-				c.builder.CreateRet(llvm.ConstInt(retType, 0, false))
-			}
-		}
+// coerceReturnValue ajusta o valor de um 'return' ao tipo de retorno da função atual,
+// aplicando as conversões numéricas e rejeitando tipos incompatíveis.
+func (c *CodeGenerator) coerceReturnValue(val llvm.Value, expr ast.Expression) llvm.Value {
+	target := c.currentFunctionReturnType
+	result, ok := c.coerceValue(val, expr, target, c.currentFunctionReturnTypeName)
+	if !ok {
+		panic(errorAt(expr, "tipo de retorno incompatível: esperado %s, recebido %s",
+			c.currentFunctionReturnTypeName, c.describeType(expr, val)))
 	}
+	return result
 }
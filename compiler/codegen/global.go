@@ -0,0 +1,107 @@
+package codegen
+
+import (
+	"taquion/compiler/ast"
+
+	"github.com/taquion-lang/go-llvm"
+)
+
+// atTopLevel informa se o código atual está fora de qualquer função, no escopo global do pacote.
+func (c *CodeGenerator) atTopLevel() bool {
+	return len(c.symbolTable) == 1
+}
+
+// genGlobalDeclaration gera um 'let' ou 'const' declarado fora de funções. Um inicializador
+// constante vira o valor inicial do global (ou, num 'const', o próprio valor do símbolo); os
+// demais são calculados na função de inicialização do pacote, chamada antes do 'main'.
+func (c *CodeGenerator) genGlobalDeclaration(name *ast.Identifier, typ, value ast.Expression, isConst bool) {
+	c.logTracef("Gerando global '%s'", name.Value)
+	c.enterInit()
+	defer func() { c.initBlock = c.builder.GetInsertBlock() }()
+
+	val, typeName := c.genDeclarationValue(name, typ, value)
+	c.defineGlobal(name, val, typeName, isConst)
+}
+
+// defineGlobal declara o global name com o valor inicial val. O builder deve estar na função de
+// inicialização do pacote.
+func (c *CodeGenerator) defineGlobal(name *ast.Identifier, val llvm.Value, typeName string, isConst bool) {
+	valType := c.GetValueTypeSafe(val)
+	folded := !val.IsAConstant().IsNil()
+	if isConst && folded && valType.TypeKind() != llvm.ArrayTypeKind {
+		c.setSymbol(name.Value, SymbolEntry{Value: val, Typ: valType, TypeName: typeName, IsLiteral: true})
+		return
+	}
+
+	// Assim como nas variáveis locais, o símbolo de um array guarda o ponteiro para o array.
+	var arrayType llvm.Type
+	if valType.TypeKind() == llvm.ArrayTypeKind {
+		arrayType = valType
+		arrayGlobal := c.newGlobal(c.mangle(name.Value)+".array", val, folded)
+		val, valType, folded = arrayGlobal, arrayGlobal.Type(), true
+	}
+
+	global := c.newGlobal(c.mangle(name.Value), val, folded)
+	if isConst {
+		global.SetGlobalConstant(folded)
+	}
+	c.setSymbol(name.Value, SymbolEntry{Ptr: global, Typ: valType, TypeName: typeName, ArrayType: arrayType, IsLiteral: isConst})
+}
+
+// newGlobal cria uma variável global com o valor val: como valor inicial, quando ele é constante,
+// ou guardado pela função de inicialização.
+func (c *CodeGenerator) newGlobal(name string, val llvm.Value, folded bool) llvm.Value {
+	typ := c.GetValueTypeSafe(val)
+	global := llvm.AddGlobal(c.module, typ, name)
+	global.SetLinkage(llvm.InternalLinkage)
+	if folded {
+		global.SetInitializer(val)
+	} else {
+		global.SetInitializer(llvm.ConstNull(typ))
+		c.builder.CreateStore(val, global)
+	}
+	return global
+}
+
+// enterInit posiciona o builder no fim da função de inicialização do pacote, criando-a na
+// primeira declaração global.
+func (c *CodeGenerator) enterInit() {
+	if c.initFunc.IsNil() {
+		fnType := llvm.FunctionType(c.context.VoidType(), nil, false)
+		c.initFunc = llvm.AddFunction(c.module, c.mangle("__init"), fnType)
+		c.initFunc.SetLinkage(llvm.InternalLinkage)
+		c.initBlock = c.context.AddBasicBlock(c.initFunc, "entry")
+	}
+	c.currentFunctionReturnType = c.context.VoidType()
+	c.currentFunctionReturnTypeName = ""
+	c.builder.SetInsertPointAtEnd(c.initBlock)
+}
+
+// finishInit fecha a função de inicialização do pacote atual. Quando todos os globais tinham
+// valores constantes ela fica vazia e é removida.
+func (c *CodeGenerator) finishInit() {
+	if c.initFunc.IsNil() {
+		return
+	}
+	if c.initBlock == c.initFunc.EntryBasicBlock() && c.initBlock.FirstInstruction().IsNil() {
+		c.initFunc.EraseFromParentAsFunction()
+	} else {
+		c.builder.SetInsertPointAtEnd(c.initBlock)
+		c.builder.CreateRetVoid()
+		c.initFuncs = append(c.initFuncs, c.initFunc)
+	}
+	c.initFunc, c.initBlock = llvm.Value{}, llvm.BasicBlock{}
+}
+
+// callInits chama as funções de inicialização dos pacotes no início do 'main', na ordem em
+// que os pacotes foram gerados (dependências primeiro).
+func (c *CodeGenerator) callInits(mainFunc llvm.Value) {
+	if len(c.initFuncs) == 0 {
+		return
+	}
+	c.builder.SetInsertPointBefore(mainFunc.EntryBasicBlock().FirstInstruction())
+	fnType := llvm.FunctionType(c.context.VoidType(), nil, false)
+	for _, fn := range c.initFuncs {
+		c.builder.CreateCall(fnType, fn, nil, "")
+	}
+}
@@ -0,0 +1,115 @@
+package codegen
+
+import (
+	"taquion/compiler/ast"
+
+	"github.com/taquion-lang/go-llvm"
+)
+
+// genMatchStatement gera um match. Cada braço tem o seu bloco e desvia para match_end ao
+// terminar, sem fallthrough. Sem braço '_', um valor não listado vai direto para o fim.
+func (c *CodeGenerator) genMatchStatement(node *ast.MatchStatement) {
+	c.logTracef("Gerando MatchStatement")
+
+	subject := c.genExpression(node.Subject)
+	function := c.builder.GetInsertBlock().Parent()
+
+	armBlocks := make([]llvm.BasicBlock, len(node.Arms))
+	var defaultBlock llvm.BasicBlock
+	for i, arm := range node.Arms {
+		if !arm.Default {
+			armBlocks[i] = c.context.AddBasicBlock(function, "match_arm")
+			continue
+		}
+		if !defaultBlock.IsNil() {
+			panic(errorAt(arm, "o match tem mais de um braço '_'"))
+		}
+		armBlocks[i] = c.context.AddBasicBlock(function, "match_default")
+		defaultBlock = armBlocks[i]
+	}
+	endBlock := c.context.AddBasicBlock(function, "match_end")
+	if defaultBlock.IsNil() {
+		defaultBlock = endBlock
+	}
+
+	subjectType := c.GetValueTypeSafe(subject)
+	switch {
+	case c.exprTypeName(node.Subject) == "string":
+		c.genStringMatch(node, subject, armBlocks, defaultBlock)
+	case isIntegerType(subjectType):
+		c.genIntMatch(node, subject, armBlocks, defaultBlock)
+	default:
+		panic(errorAt(node.Subject, "match não suporta valores do tipo %s", c.describeType(node.Subject, subject)))
+	}
+
+	for i, arm := range node.Arms {
+		c.builder.SetInsertPointAtEnd(armBlocks[i])
+		c.genBlockStatement(arm.Body)
+		if !isBlockTerminated(c.builder.GetInsertBlock()) {
+			c.builder.CreateBr(endBlock)
+		}
+	}
+	c.builder.SetInsertPointAtEnd(endBlock)
+}
+
+// genIntMatch despacha um match sobre inteiros, caracteres ou bools com a instrução switch.
+// Os casos precisam ser constantes e não podem se repetir.
+func (c *CodeGenerator) genIntMatch(node *ast.MatchStatement, subject llvm.Value, armBlocks []llvm.BasicBlock, defaultBlock llvm.BasicBlock) {
+	subjectType := c.GetValueTypeSafe(subject)
+	subjectName := c.exprTypeName(node.Subject)
+
+	count := 0
+	for _, arm := range node.Arms {
+		count += len(arm.Patterns)
+	}
+	sw := c.builder.CreateSwitch(subject, defaultBlock, count)
+
+	seen := map[int64]ast.Expression{}
+	for i, arm := range node.Arms {
+		for _, pattern := range arm.Patterns {
+			val := c.genExpression(pattern)
+			if val.IsAConstantInt().IsNil() {
+				panic(errorAt(pattern, "o caso %s do match não é uma constante", pattern.String()))
+			}
+			converted, ok := c.coerceValue(val, pattern, subjectType, subjectName)
+			if !ok {
+				panic(errorAt(pattern, "o caso %s não é compatível com o valor do match (%s)", pattern.String(), c.describeType(node.Subject, subject)))
+			}
+			key := converted.SExtValue()
+			if prev, dup := seen[key]; dup {
+				panic(errorAt(pattern, "caso duplicado no match: %s (já usado em %s)", pattern.String(), prev.Pos()))
+			}
+			seen[key] = pattern
+			sw.AddCase(converted, armBlocks[i])
+		}
+	}
+}
+
+// genStringMatch despacha um match sobre strings comparando o valor com cada literal (strcmp),
+// na ordem dos braços.
+func (c *CodeGenerator) genStringMatch(node *ast.MatchStatement, subject llvm.Value, armBlocks []llvm.BasicBlock, defaultBlock llvm.BasicBlock) {
+	i8PtrType := llvm.PointerType(c.context.Int8Type(), 0)
+	strcmpType := llvm.FunctionType(c.context.Int32Type(), []llvm.Type{i8PtrType, i8PtrType}, false)
+	function := c.builder.GetInsertBlock().Parent()
+
+	seen := map[string]ast.Expression{}
+	for i, arm := range node.Arms {
+		for _, pattern := range arm.Patterns {
+			lit, ok := pattern.(*ast.StringLiteral)
+			if !ok {
+				panic(errorAt(pattern, "os casos de um match sobre strings devem ser literais de string"))
+			}
+			if prev, dup := seen[lit.Value]; dup {
+				panic(errorAt(pattern, "caso duplicado no match: %s (já usado em %s)", pattern.String(), prev.Pos()))
+			}
+			seen[lit.Value] = pattern
+
+			cmp := c.builder.CreateCall(strcmpType, c.strcmpFunc, []llvm.Value{subject, c.genExpression(lit)}, "match_cmp")
+			eq := c.builder.CreateICmp(llvm.IntEQ, cmp, llvm.ConstInt(c.context.Int32Type(), 0, false), "match_eq")
+			next := c.context.AddBasicBlock(function, "match_next")
+			c.builder.CreateCondBr(eq, armBlocks[i], next)
+			c.builder.SetInsertPointAtEnd(next)
+		}
+	}
+	c.builder.CreateBr(defaultBlock)
+}
@@ -14,6 +14,8 @@ func (c *CodeGenerator) genStatement(stmt ast.Statement) {
 	switch node := stmt.(type) {
 	case *ast.PackageStatement:
 		c.genPackageStatement(node)
+	case *ast.ImportStatement:
+		c.genImportStatement(node)
 	case *ast.LetStatement:
 		c.genLetStatement(node)
 	case *ast.ConstStatement:
@@ -28,85 +30,169 @@ func (c *CodeGenerator) genStatement(stmt ast.Statement) {
 		c.genFunctionDeclaration(node)
 	case *ast.WhileStatement:
 		c.genWhileStatement(node)
+	case *ast.ForStatement:
+		c.genForStatement(node)
+	case *ast.ForInStatement:
+		c.genForInStatement(node)
 	case *ast.BreakStatement:
 		c.genBreakStatement(node)
 	case *ast.ContinueStatement:
 		c.genContinueStatement(node)
+	case *ast.MatchStatement:
+		c.genMatchStatement(node)
 	case *ast.TypeDeclaration:
 		c.genTypeDeclaration(node)
 	default:
-		panic(fmt.Sprintf("Declaração não suportada: %T\n", node))
+		panic(errorAt(stmt, "Declaração não suportada: %T", node))
 	}
 }
 
-// genPackageStatement ignora a declaração de pacote.
+// genPackageStatement ignora a declaração de pacote; o loader já conferiu o nome.
 func (c *CodeGenerator) genPackageStatement(node *ast.PackageStatement) {
-	c.logTrace(fmt.Sprintf("Ignorando declaração de pacote: package %s", node.Name.Value))
+	c.logTracef("Ignorando declaração de pacote: package %s", node.Name.Value)
+}
+
+// genImportStatement torna o pacote importado acessível pelo seu nome no arquivo atual.
+func (c *CodeGenerator) genImportStatement(node *ast.ImportStatement) {
+	name := node.PackageName()
+	if _, loaded := c.packageScopes[node.Path.Value]; !loaded {
+		panic(errorAt(node.Path, "pacote não carregado: %q", node.Path.Value))
+	}
+	if previous, dup := c.imports[name]; dup {
+		panic(errorAt(node.Path, "o nome %s já se refere ao pacote %q importado neste arquivo", name, previous))
+	}
+	c.imports[name] = node.Path.Value
 }
 
 // genLetStatement gera código para a declaração de variáveis `let`.
 func (c *CodeGenerator) genLetStatement(node *ast.LetStatement) {
-	c.logTrace(fmt.Sprintf("Gerando declaração 'let' para a variável '%s'", node.Name.Value))
+	if len(node.Names) > 0 {
+		c.genDestructuringLet(node)
+		return
+	}
+	c.logTracef("Gerando declaração 'let' para a variável '%s'", node.Name.Value)
+	if c.atTopLevel() {
+		c.genGlobalDeclaration(node.Name, node.Type, node.Value, false)
+		return
+	}
+
+	val, typeName := c.genDeclarationValue(node.Name, node.Type, node.Value)
+	c.defineLocal(node.Name, val, typeName, false)
+}
 
-	val := c.genExpression(node.Value)
+// defineLocal declara a variável local name com o valor inicial val. Uma constante com valor
+// conhecido em tempo de compilação guarda o próprio valor; as demais viram variáveis imutáveis.
+func (c *CodeGenerator) defineLocal(name *ast.Identifier, val llvm.Value, typeName string, isConst bool) {
 	valType := c.GetValueTypeSafe(val)
 	if valType.IsNil() {
-		panic(fmt.Sprintf("tipo inválido para a variável 'let' %s", node.Name.Value))
+		panic(errorAt(name, "tipo inválido para a variável 'let' %s", name.Value))
+	}
+	if isConst && !val.IsAConstant().IsNil() && valType.TypeKind() != llvm.ArrayTypeKind {
+		c.setSymbol(name.Value, SymbolEntry{Value: val, Typ: valType, TypeName: typeName, IsLiteral: true})
+		return
+	}
+
+	// Arrays vivem na pilha e a variável guarda o ponteiro para eles: o valor do array
+	// (uma cópia, no caso de outra variável) é guardado em uma alocação própria.
+	var arrayType llvm.Type
+	if valType.TypeKind() == llvm.ArrayTypeKind {
+		arrayType = valType
+		arrayPtr := c.allocLocal(name.Value, arrayType, name.Value+"_array")
+		c.builder.CreateStore(val, arrayPtr)
+		val, valType = arrayPtr, arrayPtr.Type()
 	}
 
-	ptr := c.builder.CreateAlloca(valType, node.Name.Value)
+	ptr := c.allocLocal(name.Value, valType, name.Value)
 	c.builder.CreateStore(val, ptr)
-	c.logTrace(fmt.Sprintf("DEBUG: Alocando ponteiro para a variável: %v", ptr))
+	c.logTracef("DEBUG: Alocando ponteiro para a variável: %v", ptr)
 
-	entry := SymbolEntry{Ptr: ptr, Typ: valType, IsLiteral: false}
+	entry := SymbolEntry{Ptr: ptr, Typ: valType, ArrayType: arrayType, IsLiteral: isConst}
 
-	if compLit, ok := node.Value.(*ast.CompositeLiteral); ok {
-		entry.TypeName = compLit.TypeName.Value
+	entry.TypeName = typeName
+	c.setSymbol(name.Value, entry)
+}
+
+// genConstStatement gera código para a declaração de constantes.
+func (c *CodeGenerator) genConstStatement(node *ast.ConstStatement) {
+	c.logTracef("Gerando declaração 'const' para a constante '%s'", node.Name.Value)
+	if c.atTopLevel() {
+		c.genGlobalDeclaration(node.Name, node.Type, node.Value, true)
+		return
 	}
+	val, typeName := c.genDeclarationValue(node.Name, node.Type, node.Value)
+	c.defineLocal(node.Name, val, typeName, true)
+}
 
-	switch valueNode := node.Value.(type) {
-	case *ast.ArrayLiteral:
-		elemCount := len(valueNode.Elements)
-		elemType := c.context.Int32Type()
-		entry.ArrayType = llvm.ArrayType(elemType, elemCount)
-	case *ast.Identifier:
-		if symbol, ok := c.getSymbol(valueNode.Value); ok {
-			if !symbol.ArrayType.IsNil() {
-				entry.ArrayType = symbol.ArrayType
-			}
+// genDeclarationValue gera o valor inicial de um 'let' ou 'const' e devolve também o nome do
+// seu tipo na fonte. Com tipo anotado, o valor é convertido para ele (ou é o valor zero do
+// tipo, se não houver inicializador); sem anotação, o tipo vem do próprio valor.
+func (c *CodeGenerator) genDeclarationValue(name *ast.Identifier, typ, value ast.Expression) (llvm.Value, string) {
+	if typ == nil {
+		val := c.genStoredValue(value, llvm.Type{}, "")
+		if c.GetValueTypeSafe(val).TypeKind() == llvm.VoidTypeKind {
+			panic(errorAt(value, "a expressão não devolve valor para %s", name.Value))
+		}
+		typeName := c.exprTypeName(value)
+		if elemNames, isTuple := c.tupleElemNames[typeName]; isTuple {
+			panic(errorAt(value, "a expressão devolve %d valores; use 'let a, b = ...' para recebê-los", len(elemNames)))
 		}
+		return val, typeName
 	}
-	c.setSymbol(node.Name.Value, entry)
-}
 
-// genConstStatement gera código para a declaração de constantes.
-func (c *CodeGenerator) genConstStatement(node *ast.ConstStatement) {
-	c.logTrace(fmt.Sprintf("Gerando declaração 'const' para a constante '%s'", node.Name.Value))
-	val := c.genExpression(node.Value)
-	isConst := !val.IsAConstant().IsNil()
-	typ := c.GetValueTypeSafe(val)
-
-	if isConst {
-		c.logTrace(fmt.Sprintf("DEBUG: Constante '%s' é um literal. Armazenando valor diretamente.", node.Name.Value))
-		c.setSymbol(node.Name.Value, SymbolEntry{Value: val, Typ: typ, IsLiteral: true})
+	declType := c.lookupLLVMType(typ)
+	typeName := c.typeName(typ)
+	if value == nil {
+		return c.zeroValue(declType, typeName), typeName
+	}
+	var val llvm.Value
+	if declType.TypeKind() == llvm.ArrayTypeKind {
+		val = c.genStoredValue(value, declType, typeName)
 	} else {
-		c.logTrace(fmt.Sprintf("DEBUG: Constante '%s' é um resultado de instrução, tratando como variável imutável.", node.Name.Value))
-		ptr := c.builder.CreateAlloca(typ, node.Name.Value)
-		c.builder.CreateStore(val, ptr)
-		c.setSymbol(node.Name.Value, SymbolEntry{Ptr: ptr, Typ: typ, IsLiteral: true})
+		val = c.genExpression(value)
+	}
+	converted, ok := c.coerceValue(val, value, declType, typeName)
+	if !ok {
+		panic(errorAt(value, "não é possível usar %s como valor de %s do tipo %s",
+			c.describeType(value, val), name.Value, typeName))
 	}
+	return converted, typeName
 }
 
 // genReturnStatement gera código para a instrução `return`.
 func (c *CodeGenerator) genReturnStatement(node *ast.ReturnStatement) {
-	c.logTrace("Gerando declaração 'return'")
-	val := c.genExpression(node.ReturnValue)
-	c.builder.CreateRet(val)
+	c.logTracef("Gerando declaração 'return'")
+	retType := c.currentFunctionReturnType
+	if node.ReturnValue == nil {
+		switch {
+		case retType.TypeKind() == llvm.VoidTypeKind:
+			c.builder.CreateRetVoid()
+		case c.currentFunctionReturnTypeName == "":
+			// Assim como ao cair no fim, um 'return' sem valor no 'main' devolve zero.
+			c.builder.CreateRet(llvm.ConstInt(retType, 0, false))
+		default:
+			panic(errorAt(node, "'return' sem valor em função que devolve %s", c.currentFunctionReturnTypeName))
+		}
+		return
+	}
+	if retType.TypeKind() == llvm.VoidTypeKind {
+		panic(errorAt(node.ReturnValue, "função sem tipo de retorno não pode devolver um valor"))
+	}
+	if tuple, ok := node.ReturnValue.(*ast.TupleExpression); ok {
+		c.genTupleReturn(tuple)
+		return
+	}
+	// Arrays são manipulados pelo ponteiro da pilha; o retorno leva uma cópia do valor.
+	val := c.genStoredValue(node.ReturnValue, retType, c.currentFunctionReturnTypeName)
+	c.builder.CreateRet(c.coerceReturnValue(val, node.ReturnValue))
 }
 
 // genExpressionStatement gera código para uma declaração de expressão.
 func (c *CodeGenerator) genExpressionStatement(node *ast.ExpressionStatement) {
-	c.logTrace("Gerando declaração de expressão")
+	c.logTracef("Gerando declaração de expressão")
+	if ifExpr, ok := node.Expression.(*ast.IfExpression); ok {
+		c.genIf(ifExpr, false) // como instrução, o 'if' não precisa produzir valor
+		return
+	}
 	c.genExpression(node.Expression)
 }
 
@@ -114,11 +200,11 @@ func (c *CodeGenerator) genExpressionStatement(node *ast.ExpressionStatement) {
 func (c *CodeGenerator) genBlockStatement(node *ast.BlockStatement) {
 	c.pushScope()
 	defer c.popScope()
-	c.logTrace("Gerando declaração de bloco")
+	c.logTracef("Gerando declaração de bloco")
 
 	for _, s := range node.Statements {
 		if isBlockTerminated(c.builder.GetInsertBlock()) {
-			c.logTrace("Bloco já terminado, pulando o resto das declarações.")
+			c.logTracef("Bloco já terminado, pulando o resto das declarações.")
 			break
 		}
 		c.genStatement(s)
@@ -131,7 +217,7 @@ func (c *CodeGenerator) genConstructor(node *ast.TypeDeclaration) {
 	structName := node.Name.Value
 	constructorName := structName + ".constructor"
 
-	structType := c.getLLVMStructType(structName)
+	structType := c.getLLVMStructType(c.mangle(structName))
 	ptrType := llvm.PointerType(structType, 0)
 
 	paramTypes := []llvm.Type{ptrType}
@@ -140,7 +226,7 @@ func (c *CodeGenerator) genConstructor(node *ast.TypeDeclaration) {
 	}
 
 	fnType := llvm.FunctionType(c.context.VoidType(), paramTypes, false)
-	constructor := llvm.AddFunction(c.module, constructorName, fnType)
+	constructor := llvm.AddFunction(c.module, c.mangle(constructorName), fnType)
 
 	// ▼▼▼ ADD THIS BLOCK ▼▼▼
 	// Name the parameters so they can be looked up later.
@@ -170,45 +256,152 @@ func (c *CodeGenerator) genWhileStatement(node *ast.WhileStatement) {
 	loopBlock := c.context.AddBasicBlock(function, "loop_body")
 	endBlock := c.context.AddBasicBlock(function, "loop_end")
 
-	prevLoopCond := c.loopCondBlock
-	prevLoopEnd := c.loopEndBlock
-	c.loopCondBlock = condBlock
-	c.loopEndBlock = endBlock
-
 	c.builder.CreateBr(condBlock)
 	c.builder.SetInsertPointAtEnd(condBlock)
 
-	cond := c.genExpression(node.Condition)
-	condType := c.GetValueTypeSafe(cond)
-	if condType.TypeKind() != llvm.IntegerTypeKind || condType.IntTypeWidth() != 1 {
-		panic(fmt.Sprintf("expressão condicional inválida no while, esperava i1, recebeu %v", condType))
-	}
-
+	cond := c.genLoopCondition(node.Condition, "while")
 	c.builder.CreateCondBr(cond, loopBlock, endBlock)
 	c.builder.SetInsertPointAtEnd(loopBlock)
 
-	c.genStatement(node.Body)
+	c.genLoopBody(node.Body, loopContext{continueBlock: condBlock, breakBlock: endBlock})
 	if !isBlockTerminated(c.builder.GetInsertBlock()) {
 		c.builder.CreateBr(condBlock)
 	}
 
 	c.builder.SetInsertPointAtEnd(endBlock)
-	c.loopCondBlock = prevLoopCond
-	c.loopEndBlock = prevLoopEnd
+}
+
+// genForStatement gera o 'for' no estilo C. 'continue' desvia para o pós-comando, que então
+// volta para a condição.
+func (c *CodeGenerator) genForStatement(node *ast.ForStatement) {
+	// Variáveis declaradas na inicialização pertencem ao laço.
+	c.pushScope()
+	defer c.popScope()
+	if node.Init != nil {
+		c.genStatement(node.Init)
+	}
+
+	function := c.builder.GetInsertBlock().Parent()
+	condBlock := c.context.AddBasicBlock(function, "for_cond")
+	bodyBlock := c.context.AddBasicBlock(function, "for_body")
+	postBlock := c.context.AddBasicBlock(function, "for_post")
+	endBlock := c.context.AddBasicBlock(function, "for_end")
+
+	c.builder.CreateBr(condBlock)
+	c.builder.SetInsertPointAtEnd(condBlock)
+	if node.Condition != nil {
+		c.builder.CreateCondBr(c.genLoopCondition(node.Condition, "for"), bodyBlock, endBlock)
+	} else {
+		c.builder.CreateBr(bodyBlock)
+	}
+
+	c.builder.SetInsertPointAtEnd(bodyBlock)
+	c.genLoopBody(node.Body, loopContext{continueBlock: postBlock, breakBlock: endBlock})
+	if !isBlockTerminated(c.builder.GetInsertBlock()) {
+		c.builder.CreateBr(postBlock)
+	}
+
+	c.builder.SetInsertPointAtEnd(postBlock)
+	if node.Post != nil {
+		c.genStatement(node.Post)
+	}
+	c.builder.CreateBr(condBlock)
+
+	c.builder.SetInsertPointAtEnd(endBlock)
+}
+
+// genForInStatement gera 'for [i,] v in arr': um contador percorre o array e, a cada iteração,
+// o elemento é copiado para a variável do laço.
+func (c *CodeGenerator) genForInStatement(node *ast.ForInStatement) {
+	arrayPtr, arrayType := c.genArrayAddress(node.Iterable)
+	int32Type := c.context.Int32Type()
+
+	c.pushScope()
+	defer c.popScope()
+
+	// As variáveis do laço são alocadas uma vez, antes dele, e reescritas a cada iteração.
+	counterPtr := c.builder.CreateAlloca(int32Type, "for_idx")
+	c.builder.CreateStore(llvm.ConstInt(int32Type, 0, false), counterPtr)
+
+	elemType := arrayType.ElementType()
+	valuePtr := c.allocLocal(node.Value.Value, elemType, node.Value.Value)
+	valueEntry := SymbolEntry{Ptr: valuePtr, Typ: elemType, TypeName: elemTypeName(c.exprTypeName(node.Iterable))}
+	if elemType.TypeKind() == llvm.ArrayTypeKind {
+		// Como em 'let', uma variável de array guarda o ponteiro para o array.
+		holder := c.allocLocal(node.Value.Value, valuePtr.Type(), node.Value.Value+"_ptr")
+		c.builder.CreateStore(valuePtr, holder)
+		valueEntry.Ptr, valueEntry.Typ, valueEntry.ArrayType = holder, valuePtr.Type(), elemType
+	}
+	c.setSymbol(node.Value.Value, valueEntry)
+
+	var indexPtr llvm.Value
+	if node.Index != nil {
+		indexPtr = c.allocLocal(node.Index.Value, int32Type, node.Index.Value)
+		c.setSymbol(node.Index.Value, SymbolEntry{Ptr: indexPtr, Typ: int32Type, TypeName: "int"})
+	}
+
+	function := c.builder.GetInsertBlock().Parent()
+	condBlock := c.context.AddBasicBlock(function, "forin_cond")
+	bodyBlock := c.context.AddBasicBlock(function, "forin_body")
+	nextBlock := c.context.AddBasicBlock(function, "forin_next")
+	endBlock := c.context.AddBasicBlock(function, "forin_end")
+
+	c.builder.CreateBr(condBlock)
+	c.builder.SetInsertPointAtEnd(condBlock)
+	counter := c.builder.CreateLoad(int32Type, counterPtr, "for_idx_val")
+	length := llvm.ConstInt(int32Type, uint64(arrayType.ArrayLength()), false)
+	c.builder.CreateCondBr(c.builder.CreateICmp(llvm.IntSLT, counter, length, "forin_more"), bodyBlock, endBlock)
+
+	c.builder.SetInsertPointAtEnd(bodyBlock)
+	counter = c.builder.CreateLoad(int32Type, counterPtr, "for_idx_val")
+	indices := []llvm.Value{llvm.ConstInt(int32Type, 0, false), counter}
+	elemPtr := c.builder.CreateInBoundsGEP(arrayType, arrayPtr, indices, "forin_elem_ptr")
+	c.builder.CreateStore(c.builder.CreateLoad(elemType, elemPtr, "forin_elem"), valuePtr)
+	if node.Index != nil {
+		c.builder.CreateStore(counter, indexPtr)
+	}
+	c.genLoopBody(node.Body, loopContext{continueBlock: nextBlock, breakBlock: endBlock})
+	if !isBlockTerminated(c.builder.GetInsertBlock()) {
+		c.builder.CreateBr(nextBlock)
+	}
+
+	c.builder.SetInsertPointAtEnd(nextBlock)
+	counter = c.builder.CreateLoad(int32Type, counterPtr, "for_idx_val")
+	c.builder.CreateStore(c.builder.CreateAdd(counter, llvm.ConstInt(int32Type, 1, false), "for_idx_next"), counterPtr)
+	c.builder.CreateBr(condBlock)
+
+	c.builder.SetInsertPointAtEnd(endBlock)
+}
+
+// genLoopCondition gera a condição de um laço, exigindo que ela seja booleana (i1).
+func (c *CodeGenerator) genLoopCondition(expr ast.Expression, loop string) llvm.Value {
+	cond := c.genExpression(expr)
+	condType := c.GetValueTypeSafe(cond)
+	if condType.TypeKind() != llvm.IntegerTypeKind || condType.IntTypeWidth() != 1 {
+		panic(errorAt(expr, "expressão condicional inválida no %s, esperava i1, recebeu %v", loop, condType))
+	}
+	return cond
+}
+
+// genLoopBody gera o corpo de um laço com loop no topo da pilha de laços.
+func (c *CodeGenerator) genLoopBody(body *ast.BlockStatement, loop loopContext) {
+	c.loops = append(c.loops, loop)
+	defer func() { c.loops = c.loops[:len(c.loops)-1] }()
+	c.genStatement(body)
 }
 
 func (c *CodeGenerator) genBreakStatement(node *ast.BreakStatement) {
-	if c.loopEndBlock.IsNil() {
-		panic("'break' fora de um loop")
+	if len(c.loops) == 0 {
+		panic(errorAt(node, "'break' fora de um loop"))
 	}
-	c.builder.CreateBr(c.loopEndBlock)
+	c.builder.CreateBr(c.loops[len(c.loops)-1].breakBlock)
 }
 
 func (c *CodeGenerator) genContinueStatement(node *ast.ContinueStatement) {
-	if c.loopCondBlock.IsNil() {
-		panic("'continue' fora de um loop")
+	if len(c.loops) == 0 {
+		panic(errorAt(node, "'continue' fora de um loop"))
 	}
-	c.builder.CreateBr(c.loopCondBlock)
+	c.builder.CreateBr(c.loops[len(c.loops)-1].continueBlock)
 }
 
 // In codegen/statement.go
@@ -237,11 +430,7 @@ func (c *CodeGenerator) genTypeDeclaration(node *ast.TypeDeclaration) {
 		params = append(params, selfParam)
 
 		for _, field := range node.Fields {
-			fieldType, ok := field.Type.(*ast.Identifier)
-			if !ok {
-				panic(fmt.Sprintf("tipo de campo não-identificador não suportado em métodos: %s", field.Name.Value))
-			}
-			fieldParam := &ast.Identifier{Token: field.Name.Token, Value: field.Name.Value, Type: fieldType}
+			fieldParam := &ast.Identifier{Token: field.Name.Token, Value: field.Name.Value, Type: field.Type}
 			params = append(params, fieldParam)
 		}
 
@@ -251,6 +440,7 @@ func (c *CodeGenerator) genTypeDeclaration(node *ast.TypeDeclaration) {
 			Token:      method.Token,
 			Name:       &ast.Identifier{Token: method.Token, Value: methodName},
 			Parameters: params,
+			ReturnType: method.ReturnType,
 			Body:       method.Body,
 		}
 
@@ -314,45 +504,72 @@ func rewriteExpr(expr ast.Expression) ast.Expression {
 }
 
 func (c *CodeGenerator) genMemberExpression(node *ast.MemberExpression) llvm.Value {
-	c.logTrace(fmt.Sprintf(
+	if entry, ok := c.getQualifiedSymbol(node); ok {
+		return c.loadSymbol(entry, node.Property.Value)
+	}
+	c.logTracef(
 		"DEBUG: Acessando campo '%s' do objeto '%s'",
 		node.Property.Value,
 		node.Object.String(),
-	))
+	)
 
-	// 1. Obtém o ponteiro para o objeto struct (ex: 'self')
-	objectIdent, ok := node.Object.(*ast.Identifier)
-	if !ok {
-		// Por enquanto, só suportamos acesso a membros de identificadores diretos.
-		panic("acesso a membro em um não-identificador ainda não é suportado")
-	}
-	entry, ok := c.getSymbol(objectIdent.Value)
-	if !ok {
-		panic(fmt.Sprintf("objeto desconhecido: %s", objectIdent.Value))
-	}
-
-	// O 'Ptr' na tabela de símbolos é o ponteiro para a nossa struct alocada.
-	objectPtr := entry.Ptr
+	elementPtr, fieldType := c.genMemberAddress(node)
+	return c.builder.CreateLoad(fieldType, elementPtr, node.Property.Value+"_val")
+}
 
-	// 2. Get the struct type and find the field's numerical index.
-	structType := objectPtr.Type().ElementType()
-	structName := entry.TypeName // Use the TypeName from the symbol table
+// genMemberAddress calcula o endereço do campo acessado e devolve também o seu tipo.
+func (c *CodeGenerator) genMemberAddress(node *ast.MemberExpression) (llvm.Value, llvm.Type) {
+	// 1. Obtém o ponteiro para o objeto struct (ex: 'self', 'ps[0]', 'a.b')
+	objectPtr, structType := c.genStructAddress(node.Object)
 
+	// 2. Descobre o nome do tipo da struct e o índice numérico do campo.
+	structName := c.exprTypeName(node.Object)
 	if structName == "" {
-		panic(fmt.Sprintf("não foi possível determinar o nome do tipo para o objeto '%s'", objectIdent.Value))
+		panic(errorAt(node.Object, "não foi possível determinar o nome do tipo para o objeto '%s'", node.Object.String()))
 	}
 
 	fieldIndex, ok := c.structFieldIndices[structName][node.Property.Value]
 	if !ok {
-		panic(fmt.Sprintf("campo '%s' não encontrado no tipo '%s'", node.Property.Value, structName))
+		panic(errorAt(node.Property, "campo '%s' não encontrado no tipo '%s'", node.Property.Value, structName))
 	}
 
 	// 3. Usa CreateStructGEP para obter um ponteiro para o campo específico.
 	elementPtr := c.builder.CreateStructGEP(structType, objectPtr, fieldIndex, node.Property.Value+"_ptr")
+	return elementPtr, structType.StructElementTypes()[fieldIndex]
+}
 
-	// 4. Carrega o valor do endereço de memória do campo.
-	fieldType := structType.StructElementTypes()[fieldIndex]
-	return c.builder.CreateLoad(fieldType, elementPtr, node.Property.Value+"_val")
+// genStructAddress devolve o endereço de um objeto struct e o seu tipo. O objeto pode ser uma
+// variável, um elemento de array (ps[0]), um campo (a.b) ou, copiado para uma alocação
+// temporária, qualquer outra expressão que produza uma struct.
+func (c *CodeGenerator) genStructAddress(expr ast.Expression) (llvm.Value, llvm.Type) {
+	var ptr llvm.Value
+	var typ llvm.Type
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		entry, ok := c.getSymbol(e.Value)
+		if !ok {
+			panic(errorAt(e, "objeto desconhecido: %s", e.Value))
+		}
+		ptr, typ = entry.Ptr, entry.Typ
+	case *ast.IndexExpression:
+		ptr, typ = c.genIndexAddress(e)
+	case *ast.MemberExpression:
+		if entry, ok := c.getQualifiedSymbol(e); ok {
+			ptr, typ = entry.Ptr, entry.Typ
+		} else {
+			ptr, typ = c.genMemberAddress(e)
+		}
+	}
+	if ptr.IsNil() {
+		val := c.genExpression(expr)
+		typ = c.GetValueTypeSafe(val)
+		ptr = c.builder.CreateAlloca(typ, "struct_tmp")
+		c.builder.CreateStore(val, ptr)
+	}
+	if typ.TypeKind() != llvm.StructTypeKind {
+		panic(errorAt(expr, "'%s' não é uma struct", expr.String()))
+	}
+	return ptr, typ
 }
 
 // genCompositeLiteral gera o valor de um literal composto.
@@ -361,13 +578,13 @@ func (c *CodeGenerator) genMemberExpression(node *ast.MemberExpression) llvm.Val
 
 // genCompositeLiteral gera o valor de um literal composto, lidando com qualquer ordem de campos.
 func (c *CodeGenerator) genCompositeLiteral(lit *ast.CompositeLiteral) llvm.Value {
-	typeName := lit.TypeName.Value
+	typeName := c.typeName(lit.TypeName)
 	structType := c.getLLVMStructType(typeName)
-	ctorName := fmt.Sprintf("%s.constructor", typeName)
+	ctorName := fmt.Sprintf("%s.constructor", lit.TypeName.Value)
 
-	fn := c.module.NamedFunction(ctorName)
+	fn := c.module.NamedFunction(c.mangle(ctorName))
 	if fn.IsNil() {
-		panic(fmt.Sprintf("construtor não encontrado: %s", ctorName))
+		panic(errorAt(lit, "construtor não encontrado: %s", ctorName))
 	}
 	fnParams := fn.Params()
 
@@ -390,32 +607,34 @@ func (c *CodeGenerator) genCompositeLiteral(lit *ast.CompositeLiteral) llvm.Valu
 
 		valueExpr, ok := literalFields[fieldName]
 		if !ok {
-			panic(fmt.Sprintf("campo obrigatório '%s' ausente no literal do tipo '%s'", fieldName, typeName))
+			panic(errorAt(lit, "campo obrigatório '%s' ausente no literal do tipo '%s'", fieldName, typeName))
 		}
 
-		value := c.genExpression(valueExpr)
+		expectedType := param.Type()
+		fieldTypeName := c.structFieldTypeNames[typeName][fieldName]
+		value := c.genStoredValue(valueExpr, expectedType, fieldTypeName)
 
 		// Perform type casting/truncation, just like in the previous fix.
-		expectedType := param.Type()
 		actualType := value.Type()
-		if actualType != expectedType {
-			if actualType.TypeKind() == llvm.IntegerTypeKind && expectedType.TypeKind() == llvm.IntegerTypeKind {
-				value = c.builder.CreateTrunc(value, expectedType, fieldName+"_trunc")
-			} else {
-				panic(fmt.Sprintf(
-					"tipo incompatível para o campo '%s': esperado %s, recebido %s",
-					fieldName, expectedType.String(), actualType.String(),
-				))
-			}
+		c.checkIntLiteralRange(valueExpr, expectedType, fieldTypeName)
+		switch {
+		case actualType == expectedType && c.funcTypeMatches(valueExpr, fieldTypeName):
+		case actualType != expectedType && isNumericType(actualType) && isNumericType(expectedType):
+			value = c.convertValue(value, c.exprTypeName(valueExpr), expectedType, fieldTypeName, valueExpr)
+		default:
+			panic(errorAt(valueExpr,
+				"tipo incompatível para o campo '%s': esperado %s, recebido %s",
+				fieldName, fieldTypeName, c.describeType(valueExpr, value),
+			))
 		}
 		args = append(args, value)
 		delete(literalFields, fieldName) // Remove field to detect extras later.
 	}
 
 	// 3. Ensure no extra, unknown fields were provided in the literal.
-	if len(literalFields) > 0 {
-		for extraField := range literalFields {
-			panic(fmt.Sprintf("campo desconhecido '%s' no literal para o tipo '%s'", extraField, typeName))
+	for _, kv := range lit.Fields {
+		if _, extra := literalFields[kv.Key.Value]; extra {
+			panic(errorAt(kv.Key, "campo desconhecido '%s' no literal para o tipo '%s'", kv.Key.Value, typeName))
 		}
 	}
 
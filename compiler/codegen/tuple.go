@@ -0,0 +1,84 @@
+package codegen
+
+import (
+	"strings"
+	"taquion/compiler/ast"
+
+	"github.com/taquion-lang/go-llvm"
+)
+
+// lookupTupleType resolve o tipo de retorno de uma função com múltiplos valores: os valores são
+// devolvidos juntos numa struct literal e separados de novo no ponto de chamada.
+func (c *CodeGenerator) lookupTupleType(tt *ast.TupleType) llvm.Type {
+	elemTypes := []llvm.Type{}
+	elemNames := []string{}
+	for _, t := range tt.Types {
+		elemTypes = append(elemTypes, c.lookupLLVMType(t))
+		elemNames = append(elemNames, c.typeName(t))
+	}
+	c.tupleElemNames[c.typeName(tt)] = elemNames
+	return c.context.StructType(elemTypes, false)
+}
+
+// genTupleReturn gera um 'return a, b': cada valor é convertido para o tipo declarado na sua
+// posição e todos são empacotados na struct devolvida.
+func (c *CodeGenerator) genTupleReturn(tuple *ast.TupleExpression) {
+	elemNames, ok := c.tupleElemNames[c.currentFunctionReturnTypeName]
+	if !ok {
+		panic(errorAt(tuple, "a função devolve um único valor, mas o 'return' tem %d", len(tuple.Elements)))
+	}
+	if len(elemNames) != len(tuple.Elements) {
+		panic(errorAt(tuple, "a função devolve %d valores, mas o 'return' tem %d", len(elemNames), len(tuple.Elements)))
+	}
+
+	retType := c.currentFunctionReturnType
+	elemTypes := retType.StructElementTypes()
+	result := llvm.Undef(retType)
+	for i, el := range tuple.Elements {
+		val := c.genStoredValue(el, elemTypes[i], elemNames[i])
+		converted, ok := c.coerceValue(val, el, elemTypes[i], elemNames[i])
+		if !ok {
+			panic(errorAt(el, "tipo de retorno incompatível na posição %d: esperado %s, recebido %s",
+				i+1, elemNames[i], c.describeType(el, val)))
+		}
+		result = c.builder.CreateInsertValue(result, converted, i, "")
+	}
+	c.builder.CreateRet(result)
+}
+
+// genDestructuringLet gera 'let q, r = f()': cada nome recebe um dos valores devolvidos, e '_'
+// descarta o valor da sua posição. Fora de funções os nomes viram globais do pacote.
+func (c *CodeGenerator) genDestructuringLet(node *ast.LetStatement) {
+	names := []string{}
+	for _, n := range node.Names {
+		names = append(names, n.Value)
+	}
+	c.logTracef("Gerando desestruturação 'let' para %s", strings.Join(names, ", "))
+
+	topLevel := c.atTopLevel()
+	if topLevel {
+		c.enterInit()
+		defer func() { c.initBlock = c.builder.GetInsertBlock() }()
+	}
+
+	tuple := c.genExpression(node.Value)
+	elemNames, ok := c.tupleElemNames[c.exprTypeName(node.Value)]
+	if !ok {
+		panic(errorAt(node.Value, "a expressão não devolve múltiplos valores para %s", strings.Join(names, ", ")))
+	}
+	if len(elemNames) != len(node.Names) {
+		panic(errorAt(node.Value, "a expressão devolve %d valores, mas o 'let' declara %d nomes", len(elemNames), len(node.Names)))
+	}
+
+	for i, name := range node.Names {
+		if name.Value == "_" {
+			continue
+		}
+		val := c.builder.CreateExtractValue(tuple, i, name.Value)
+		if topLevel {
+			c.defineGlobal(name, val, elemNames[i], false)
+		} else {
+			c.defineLocal(name, val, elemNames[i], false)
+		}
+	}
+}
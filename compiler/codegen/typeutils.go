@@ -2,6 +2,7 @@ package codegen
 
 import (
 	"fmt"
+	"strings"
 	"taquion/compiler/ast"
 
 	"github.com/taquion-lang/go-llvm"
@@ -22,40 +23,463 @@ func (c *CodeGenerator) getLLVMStructType(name string) llvm.Type {
 func (c *CodeGenerator) lookupLLVMType(t ast.Expression) llvm.Type {
 	switch tt := t.(type) {
 	case *ast.Identifier:
-		switch tt.Value {
-		case "int", "int32":
-			return c.context.Int32Type()
-		case "int8":
-			return c.context.Int8Type()
-		case "bool":
-			return c.context.Int1Type()
-		case "string":
-			return llvm.PointerType(c.context.Int8Type(), 0)
-		default:
-			// struct definida pelo usuário
-			return c.getLLVMStructType(tt.Value)
+		if prim, ok := c.primitiveType(tt.Value); ok {
+			return prim
 		}
+		// struct definida pelo usuário
+		if _, ok := c.structTypes[c.typeName(tt)]; !ok {
+			panic(errorAt(tt, "tipo desconhecido: %s", tt.Value))
+		}
+		return c.getLLVMStructType(c.typeName(tt))
+	case *ast.MemberExpression:
+		// struct de um pacote importado (geo.Ponto)
+		name := c.typeName(tt)
+		if _, ok := c.structTypes[name]; !ok {
+			panic(errorAt(tt, "tipo desconhecido: %s", tt.String()))
+		}
+		return c.getLLVMStructType(name)
+	case *ast.FuncType:
+		return c.lookupFuncType(tt)
+	case *ast.TupleType:
+		return c.lookupTupleType(tt)
+	case *ast.ArrayType:
+		length, ok := tt.Len.(*ast.IntegerLiteral)
+		if !ok {
+			panic(errorAt(tt.Len, "o tamanho de um array deve ser um inteiro não negativo"))
+		}
+		return llvm.ArrayType(c.lookupLLVMType(tt.Elem), int(length.Value))
+	default:
+		panic(errorAt(t, "tipo não suportado: %T", t))
+	}
+}
+
+// typeName devolve o nome canônico de um tipo da fonte, usado no TypeName dos símbolos e como
+// chave das tabelas de structs. As structs de um pacote importado levam o prefixo do pacote
+// (geo.Ponto), tanto dentro do próprio pacote quanto nos pacotes que o importam.
+func (c *CodeGenerator) typeName(t ast.Expression) string {
+	switch tt := t.(type) {
+	case *ast.Identifier:
+		if _, ok := c.primitiveType(tt.Value); ok {
+			return tt.Value
+		}
+		return c.mangle(tt.Value)
+	case *ast.MemberExpression:
+		pkgIdent, ok := tt.Object.(*ast.Identifier)
+		if !ok {
+			break
+		}
+		importPath, imported := c.imports[pkgIdent.Value]
+		if !imported {
+			panic(errorAt(tt, "pacote não importado: %s", pkgIdent.Value))
+		}
+		return mangleIn(importPath, tt.Property.Value)
+	case *ast.ArrayType:
+		return "[" + tt.Len.String() + "]" + c.typeName(tt.Elem)
+	case *ast.FuncType:
+		params := make([]string, len(tt.Params))
+		for i, p := range tt.Params {
+			params[i] = c.typeName(p)
+		}
+		return funcTypeName(params, c.returnTypeName(tt.ReturnType))
+	case *ast.TupleType:
+		types := make([]string, len(tt.Types))
+		for i, elem := range tt.Types {
+			types[i] = c.typeName(elem)
+		}
+		return "(" + strings.Join(types, ", ") + ")"
+	}
+	return t.String()
+}
+
+// returnTypeName devolve o nome canônico de um tipo de retorno, ou "" quando não há tipo.
+func (c *CodeGenerator) returnTypeName(t ast.Expression) string {
+	if t == nil {
+		return ""
+	}
+	return c.typeName(t)
+}
+
+// primitiveType resolve o nome de um tipo primitivo da linguagem para o tipo LLVM correspondente.
+func (c *CodeGenerator) primitiveType(name string) (llvm.Type, bool) {
+	switch name {
+	case "int", "int32", "uint", "uint32", "char", "rune":
+		return c.context.Int32Type(), true
+	case "int8", "uint8", "byte":
+		return c.context.Int8Type(), true
+	case "int64", "uint64":
+		return c.context.Int64Type(), true
+	case "bool":
+		return c.context.Int1Type(), true
+	case "float", "float64":
+		return c.context.DoubleType(), true
+	case "float32":
+		return c.context.FloatType(), true
+	case "string":
+		return llvm.PointerType(c.context.Int8Type(), 0), true
+	default:
+		return llvm.Type{}, false
+	}
+}
+
+// isUnsignedTypeName informa se o nome de tipo denota um inteiro sem sinal.
+// O LLVM não distingue sinal nos tipos inteiros, então essa informação vem do nome do tipo na fonte.
+func isUnsignedTypeName(name string) bool {
+	switch name {
+	case "uint", "uint8", "uint32", "uint64", "byte":
+		return true
+	default:
+		return false
+	}
+}
+
+// isCharTypeName informa se o nome de tipo denota um caractere (code point Unicode em i32).
+func isCharTypeName(name string) bool {
+	return name == "char" || name == "rune"
+}
+
+// elemTypeName devolve o nome do tipo dos elementos de um nome de tipo de array
+// ("[3]int" → "int", "[2][3]int" → "[3]int"), ou "" se o nome não for de um array.
+func elemTypeName(name string) string {
+	if !strings.HasPrefix(name, "[") {
+		return ""
+	}
+	return name[strings.Index(name, "]")+1:]
+}
+
+// exprTypeName devolve o nome do tipo (na linguagem fonte) de uma expressão quando ele
+// pode ser determinado estaticamente, ou "" caso contrário.
+func (c *CodeGenerator) exprTypeName(expr ast.Expression) string {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return "int"
+	case *ast.FloatLiteral:
+		return "float"
+	case *ast.StringLiteral, *ast.InterpolatedString:
+		return "string"
+	case *ast.BooleanLiteral:
+		return "bool"
+	case *ast.CharLiteral:
+		return "char"
+	case *ast.CompositeLiteral:
+		return c.typeName(e.TypeName)
+	case *ast.ArrayLiteral:
+		elem := "int"
+		if len(e.Elements) > 0 {
+			if elem = c.exprTypeName(e.Elements[0]); elem == "" {
+				return ""
+			}
+		}
+		return fmt.Sprintf("[%d]%s", len(e.Elements), elem)
+	case *ast.Identifier:
+		if entry, ok := c.getSymbol(e.Value); ok {
+			return entry.TypeName
+		}
+	case *ast.CallExpression:
+		name := e.Function.String()
+		calleeType := c.exprTypeName(e.Function)
+		if entry, isSymbol := c.getCallee(e.Function); isSymbol {
+			calleeType = entry.TypeName
+		}
+		if sig, isFunc := c.closureSignatures[calleeType]; isFunc {
+			return sig.returnTypeName
+		}
+		if _, isType := c.primitiveType(name); isType {
+			return name
+		}
+	case *ast.FunctionLiteral:
+		return c.literalTypeName(e)
+	case *ast.PrefixExpression:
+		if e.Operator == "!" {
+			return "bool"
+		}
+		return c.exprTypeName(e.Right)
+	case *ast.PostfixExpression:
+		return c.exprTypeName(e.Left)
+	case *ast.IfExpression:
+		if n := len(e.Consequence.Statements); n > 0 {
+			if last, ok := e.Consequence.Statements[n-1].(*ast.ExpressionStatement); ok {
+				return c.exprTypeName(last.Expression)
+			}
+		}
+	case *ast.IndexExpression:
+		left := c.exprTypeName(e.Left)
+		if left == "string" {
+			return "char"
+		}
+		return elemTypeName(left)
+	case *ast.MemberExpression:
+		if entry, ok := c.getQualifiedSymbol(e); ok {
+			return entry.TypeName
+		}
+		if fields, ok := c.structFieldTypeNames[c.exprTypeName(e.Object)]; ok {
+			return fields[e.Property.Value]
+		}
+	case *ast.InfixExpression:
+		switch e.Operator {
+		case "==", "!=", "<", ">", "<=", ">=", "&&", "||":
+			return "bool"
+		}
+		return c.promotedTypeName(c.exprTypeName(e.Left), c.exprTypeName(e.Right))
+	}
+	return ""
+}
+
+// promotedTypeName devolve o nome do tipo resultante de uma operação binária,
+// seguindo a mesma regra de promoção de unifyNumericOperands.
+func (c *CodeGenerator) promotedTypeName(leftName, rightName string) string {
+	lt, leftOk := c.primitiveType(leftName)
+	rt, rightOk := c.primitiveType(rightName)
+	if !leftOk || !rightOk || !isNumericType(lt) || !isNumericType(rt) {
+		return leftName
+	}
+	switch {
+	case lt == rt:
+		if isUnsignedTypeName(rightName) {
+			return rightName
+		}
+		return leftName
+	case isFloatType(lt) && isFloatType(rt):
+		if floatBits(lt) < floatBits(rt) {
+			return rightName
+		}
+		return leftName
+	case isFloatType(lt):
+		return leftName
+	case isFloatType(rt):
+		return rightName
+	case lt.IntTypeWidth() < rt.IntTypeWidth():
+		return rightName
+	default:
+		return leftName
+	}
+}
+
+// isCharExpr informa se a expressão tem tipo caractere.
+func (c *CodeGenerator) isCharExpr(expr ast.Expression) bool {
+	return isCharTypeName(c.exprTypeName(expr))
+}
+
+// isUnsignedExpr informa se a expressão tem tipo inteiro sem sinal.
+func (c *CodeGenerator) isUnsignedExpr(expr ast.Expression) bool {
+	return isUnsignedTypeName(c.exprTypeName(expr))
+}
+
+func isIntegerType(t llvm.Type) bool {
+	return !t.IsNil() && t.TypeKind() == llvm.IntegerTypeKind
+}
+
+func isFloatType(t llvm.Type) bool {
+	return !t.IsNil() && (t.TypeKind() == llvm.FloatTypeKind || t.TypeKind() == llvm.DoubleTypeKind)
+}
+
+func isNumericType(t llvm.Type) bool {
+	return isIntegerType(t) || isFloatType(t)
+}
+
+// floatBits retorna a largura em bits de um tipo de ponto flutuante.
+func floatBits(t llvm.Type) int {
+	if t.TypeKind() == llvm.FloatTypeKind {
+		return 32
+	}
+	return 64
+}
+
+// convertValue converte val para o tipo target aplicando as conversões numéricas
+// (int↔int, int↔float e float↔float). fromName e targetName são os nomes dos tipos na fonte,
+// que decidem se os inteiros são tratados com ou sem sinal. Qualquer outra combinação é um erro
+// de compilação.
+func (c *CodeGenerator) convertValue(val llvm.Value, fromName string, target llvm.Type, targetName string, node ast.Node) llvm.Value {
+	from := c.GetValueTypeSafe(val)
+	if from == target {
+		return val
+	}
+	switch {
+	case isIntegerType(from) && isIntegerType(target):
+		if from.IntTypeWidth() > target.IntTypeWidth() {
+			return c.builder.CreateTrunc(val, target, "int_trunc")
+		}
+		if from.IntTypeWidth() == 1 {
+			return c.builder.CreateZExt(val, target, "bool_ext")
+		}
+		if isUnsignedTypeName(fromName) {
+			return c.builder.CreateZExt(val, target, "uint_ext")
+		}
+		return c.builder.CreateSExt(val, target, "int_ext")
+	case isIntegerType(from) && isFloatType(target):
+		if from.IntTypeWidth() == 1 || isUnsignedTypeName(fromName) {
+			return c.builder.CreateUIToFP(val, target, "uint_to_fp")
+		}
+		return c.builder.CreateSIToFP(val, target, "int_to_fp")
+	case isFloatType(from) && isIntegerType(target):
+		if isUnsignedTypeName(targetName) {
+			return c.builder.CreateFPToUI(val, target, "fp_to_uint")
+		}
+		return c.builder.CreateFPToSI(val, target, "fp_to_int")
+	case isFloatType(from) && isFloatType(target):
+		if floatBits(from) < floatBits(target) {
+			return c.builder.CreateFPExt(val, target, "fp_ext")
+		}
+		return c.builder.CreateFPTrunc(val, target, "fp_trunc")
+	}
+	panic(errorAt(node, "não é possível converter %s para %s", from.String(), target.String()))
+}
+
+// coerceValue aplica as conversões implícitas de val para o tipo target: conversões numéricas
+// (com verificação de faixa de literais), mas nunca entre bool e números. ok é false quando os
+// tipos são incompatíveis.
+func (c *CodeGenerator) coerceValue(val llvm.Value, expr ast.Expression, target llvm.Type, targetName string) (llvm.Value, bool) {
+	from := c.GetValueTypeSafe(val)
+	isBool := func(t llvm.Type) bool { return isIntegerType(t) && t.IntTypeWidth() == 1 }
+
+	switch {
+	case from == target:
+		c.checkIntLiteralRange(expr, target, targetName)
+		return val, c.funcTypeMatches(expr, targetName)
+	case isNumericType(from) && isNumericType(target) && isBool(from) == isBool(target):
+		c.checkIntLiteralRange(expr, target, targetName)
+		return c.convertValue(val, c.exprTypeName(expr), target, targetName, expr), true
+	}
+	return val, false
+}
+
+// describeType descreve o tipo de uma expressão para mensagens de erro: o nome na fonte
+// quando conhecido, senão o tipo LLVM do valor.
+func (c *CodeGenerator) describeType(expr ast.Expression, val llvm.Value) string {
+	if name := c.exprTypeName(expr); name != "" {
+		return name
+	}
+	return c.GetValueTypeSafe(val).String()
+}
+
+// zeroValue devolve o valor inicial de uma variável declarada sem inicializador:
+// zero para números e bool, "" para strings e, em structs e arrays, o valor zero de cada
+// campo ou elemento.
+func (c *CodeGenerator) zeroValue(t llvm.Type, typeName string) llvm.Value {
+	switch {
+	case typeName == "string":
+		return c.builder.CreateGlobalStringPtr("", "empty_str")
+	case t.TypeKind() == llvm.ArrayTypeKind:
+		elem := c.zeroValue(t.ElementType(), elemTypeName(typeName))
+		elems := make([]llvm.Value, t.ArrayLength())
+		for i := range elems {
+			elems[i] = elem
+		}
+		return llvm.ConstArray(t.ElementType(), elems)
+	case t.TypeKind() == llvm.StructTypeKind:
+		indices, isStruct := c.structFieldIndices[typeName]
+		if !isStruct {
+			return llvm.ConstNull(t) // closures e tuplas
+		}
+		fieldTypes := t.StructElementTypes()
+		fields := make([]llvm.Value, len(fieldTypes))
+		for name, i := range indices {
+			fields[i] = c.zeroValue(fieldTypes[i], c.structFieldTypeNames[typeName][name])
+		}
+		return llvm.ConstNamedStruct(t, fields)
+	}
+	return llvm.ConstNull(t)
+}
+
+// checkIntLiteralRange rejeita, em tempo de compilação, literais inteiros que não cabem no
+// tipo inteiro de destino. typeName decide a faixa com ou sem sinal; quando vazio, aceita
+// qualquer valor representável na largura do tipo.
+func (c *CodeGenerator) checkIntLiteralRange(expr ast.Expression, target llvm.Type, typeName string) {
+	if !isIntegerType(target) || target.IntTypeWidth() == 1 {
+		return
+	}
+	var value uint64 // magnitude do literal
+	var text string
+	negative := false
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		value, text = e.Value, e.Token.Literal
+	case *ast.PrefixExpression:
+		lit, ok := e.Right.(*ast.IntegerLiteral)
+		if !ok || e.Operator != "-" {
+			return
+		}
+		value, text, negative = lit.Value, "-"+lit.Token.Literal, true
 	default:
-		panic(fmt.Sprintf("tipo não suportado: %T", t))
+		return
+	}
+
+	// Os limites são magnitudes: maxNeg é o maior valor aceito depois de um '-'.
+	maxPos := ^uint64(0) >> (64 - target.IntTypeWidth())
+	maxNeg := maxPos>>1 + 1
+	switch {
+	case isUnsignedTypeName(typeName):
+		maxNeg = 0
+	case typeName != "":
+		maxPos >>= 1
+	}
+	if (!negative && value > maxPos) || (negative && value > maxNeg) {
+		if typeName == "" {
+			typeName = target.String()
+		}
+		panic(errorAt(expr, "literal inteiro %s não cabe no tipo %s", text, typeName))
 	}
 }
 
+// unifyNumericOperands promove os operandos de uma operação binária para um tipo comum:
+// inteiros são estendidos para a maior largura e, se algum lado for float, ambos viram float.
+// leftName e rightName são os nomes dos tipos dos operandos na fonte.
+func (c *CodeGenerator) unifyNumericOperands(node ast.Node, left, right llvm.Value, leftName, rightName string) (llvm.Value, llvm.Value) {
+	lt, rt := c.GetValueTypeSafe(left), c.GetValueTypeSafe(right)
+	if lt == rt || !isNumericType(lt) || !isNumericType(rt) {
+		return left, right
+	}
+	toRight := func() (llvm.Value, llvm.Value) {
+		return c.convertValue(left, leftName, rt, rightName, node), right
+	}
+	toLeft := func() (llvm.Value, llvm.Value) {
+		return left, c.convertValue(right, rightName, lt, leftName, node)
+	}
+	switch {
+	case isFloatType(lt) && isFloatType(rt):
+		if floatBits(lt) < floatBits(rt) {
+			return toRight()
+		}
+		return toLeft()
+	case isFloatType(lt):
+		return toLeft()
+	case isFloatType(rt):
+		return toRight()
+	case lt.IntTypeWidth() < rt.IntTypeWidth():
+		return toRight()
+	default:
+		return toLeft()
+	}
+}
+
+// declareStructType cria o tipo nomeado da struct, ainda sem campos, para que campos e
+// assinaturas possam se referir a ele antes de ensureStructType definir o seu corpo.
+func (c *CodeGenerator) declareStructType(node *ast.TypeDeclaration) llvm.Type {
+	name := c.mangle(node.Name.Value)
+	if ty, ok := c.structTypes[name]; ok && !ty.IsNil() {
+		return ty
+	}
+	st := c.context.StructCreateNamed(name)
+	c.structTypes[name] = st
+	return st
+}
+
 // Garante que o llvm.StructType da struct já está criado e registrado.
 // Chame isso no início de genTypeDeclaration.
 func (c *CodeGenerator) ensureStructType(node *ast.TypeDeclaration) {
-	name := node.Name.Value
-	if ty, ok := c.structTypes[name]; ok && !ty.IsNil() {
+	name := c.mangle(node.Name.Value) // as tabelas de structs usam o nome canônico (ver typeName)
+	if _, defined := c.structFieldIndices[name]; defined {
 		return
 	}
 
-	st := c.context.StructCreateNamed(name)
-	c.structTypes[name] = st
+	st := c.declareStructType(node)
 	c.structFieldIndices[name] = make(map[string]int) // ▼▼▼ INICIALIZE O MAPA INTERNO ▼▼▼
+	c.structFieldTypeNames[name] = make(map[string]string)
 
 	fieldLLVM := make([]llvm.Type, len(node.Fields))
 	for i, f := range node.Fields {
 		fieldLLVM[i] = c.lookupLLVMType(f.Type)
 		c.structFieldIndices[name][f.Name.Value] = i // ▼▼▼ GUARDE O ÍNDICE DO CAMPO ▼▼▼
+		c.structFieldTypeNames[name][f.Name.Value] = c.typeName(f.Type)
 	}
 	st.StructSetBody(fieldLLVM, false)
 }
@@ -1,52 +1,235 @@
 // lexer/helpers.go
 package lexer
 
-import "taquion/compiler/token"
+import (
+	"fmt"
+	"strings"
+	"taquion/compiler/token"
+	"taquion/compiler/trace"
+	"unicode"
+	"unicode/utf8"
+)
 
-func isLetter(ch byte) bool {
-	return ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ch == '_'
+// isLetter aceita qualquer letra Unicode (ex: 'ç', 'ã') além de '_'.
+func isLetter(ch rune) bool {
+	return ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ch == '_' ||
+		(ch >= utf8.RuneSelf && unicode.IsLetter(ch))
 }
 
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
+// isIdentifierPart informa se o caractere pode aparecer após o primeiro caractere de um identificador.
+func isIdentifierPart(ch rune) bool {
+	return isLetter(ch) || isDigit(ch) || (ch >= utf8.RuneSelf && unicode.IsDigit(ch))
+}
+
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || ('a' <= ch && ch <= 'f') || ('A' <= ch && ch <= 'F')
+}
+
+// hexValue converte um dígito hexadecimal já validado em seu valor numérico.
+func hexValue(ch rune) int {
+	switch {
+	case isDigit(ch):
+		return int(ch - '0')
+	case 'a' <= ch && ch <= 'f':
+		return int(ch-'a') + 10
+	default:
+		return int(ch-'A') + 10
+	}
+}
+
+// Errors retorna os erros léxicos encontrados até o momento.
+func (l *Lexer) Errors() []string {
+	return l.errors
+}
+
+// errorAt registra um erro léxico prefixado pela posição no código fonte.
+func (l *Lexer) errorAt(pos token.Position, format string, args ...interface{}) {
+	msg := fmt.Sprintf("%s: %s", pos, fmt.Sprintf(format, args...))
+	l.tracef("Erro léxico: %s", msg)
+	l.errors = append(l.errors, msg)
+}
+
+// readChar avança para o próximo caractere, decodificando UTF-8.
+// A coluna conta caracteres (runes), enquanto position/readPosition são offsets em bytes.
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	if l.readPosition <= len(l.input) {
+		l.column++
+	}
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
+		l.position = len(l.input)
+		l.readPosition = len(l.input) + 1
+		return
 	}
+
+	ch, width := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	l.ch = ch
 	l.position = l.readPosition
-	l.readPosition++
+	l.readPosition += width
+	if ch == utf8.RuneError && width == 1 {
+		l.errorAt(l.currentPosition(), "codificação UTF-8 inválida")
+	}
 }
 
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	}
-	return l.input[l.readPosition]
+	ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return ch
+}
+
+// peekCharN retorna o n-ésimo caractere à frente do atual sem consumi-lo (peekCharN(1) == peekChar()).
+func (l *Lexer) peekCharN(n int) rune {
+	pos := l.readPosition
+	var ch rune
+	for i := 0; i < n; i++ {
+		if pos >= len(l.input) {
+			return 0
+		}
+		var width int
+		ch, width = utf8.DecodeRuneInString(l.input[pos:])
+		pos += width
+	}
+	return ch
+}
+
+// currentPosition retorna a posição do caractere atual no código fonte.
+func (l *Lexer) currentPosition() token.Position {
+	return token.Position{File: l.file, Line: l.line, Column: l.column, Offset: l.position}
 }
 
 // skipWhitespaceAndComments avança o lexer para pular espaços em branco e comentários em sequência.
-func (l *Lexer) skipWhitespaceAndComments() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' || (l.ch == '/' && l.peekChar() == '/') {
-		if l.ch == '/' && l.peekChar() == '/' {
-			l.logger.Println("Comentário '//' encontrado, pulando linha.")
+// Linhas de doc comment ('///') são acumuladas em l.docLines para serem anexadas ao próximo token;
+// uma linha em branco ou um comentário comum entre elas e o token descarta o que foi acumulado.
+// Um '///' depois de código na mesma linha é um comentário comum, não um doc comment.
+// Retorna true, sem consumir a quebra de linha, quando um ';' deve ser inserido antes dela.
+func (l *Lexer) skipWhitespaceAndComments() (insertSemi bool) {
+	newlines := 0
+	for {
+		switch {
+		case l.ch == '\n':
+			if l.insertSemi {
+				return true
+			}
+			newlines++
+			if newlines > 1 {
+				l.docLines = nil
+			}
+			l.readChar()
+		case l.ch == ' ' || l.ch == '\t' || l.ch == '\r':
+			l.readChar()
+		case l.ch == '/' && l.peekChar() == '/' && l.peekCharN(2) == '/' && l.peekCharN(3) != '/' && l.line > l.tokenLine:
+			l.readDocComment()
+			newlines = 0
+		case l.ch == '/' && l.peekChar() == '/':
+			l.tracef("Comentário '//' encontrado, pulando linha.")
+			l.docLines = nil
 			for l.ch != '\n' && l.ch != 0 {
 				l.readChar()
 			}
-		} else {
+			newlines = 0
+		case l.ch == '/' && l.peekChar() == '*':
+			l.docLines = nil
+			line := l.line
+			l.skipBlockComment()
+			// Um comentário de bloco que atravessa linhas vale como uma quebra de linha.
+			if l.insertSemi && l.line > line {
+				return true
+			}
+		case l.ch == 0:
+			return l.insertSemi
+		default:
+			return false
+		}
+	}
+}
+
+// endsStatement informa se um token, quando seguido de uma quebra de linha, termina a instrução.
+func endsStatement(t token.TokenType) bool {
+	switch t {
+	case token.IDENT, token.INT, token.FLOAT, token.STRING, token.CHAR, token.INTERP_END,
+		token.TRUE, token.FALSE, token.RETURN, token.BREAK, token.CONTINUE,
+		token.INCREMENT, token.DECREMENT, token.RPAREN, token.RBRACKET, token.RBRACE:
+		return true
+	default:
+		return false
+	}
+}
+
+// readDocComment lê uma linha de doc comment ('/// texto') e guarda o texto sem o marcador.
+func (l *Lexer) readDocComment() {
+	l.readChar()
+	l.readChar()
+	l.readChar() // consome '///'
+	if l.ch == ' ' {
+		l.readChar()
+	}
+	start := l.position
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+	line := strings.TrimRight(l.input[start:l.position], "\r")
+	l.tracef("Doc comment encontrado: %q", line)
+	l.docLines = append(l.docLines, line)
+}
+
+// skipBlockComment pula um comentário '/* ... */'. Comentários de bloco podem ser aninhados,
+// então '/* a /* b */ c */' é um único comentário.
+func (l *Lexer) skipBlockComment() {
+	start := l.currentPosition()
+	l.tracef("Comentário '/*' encontrado, pulando bloco.")
+	depth := 0
+	for {
+		switch {
+		case l.ch == 0:
+			l.errorAt(start, "comentário de bloco não terminado")
+			return
+		case l.ch == '/' && l.peekChar() == '*':
+			depth++
+			l.readChar()
+			l.readChar()
+		case l.ch == '*' && l.peekChar() == '/':
+			depth--
+			l.readChar()
+			l.readChar()
+			if depth == 0 {
+				return
+			}
+		default:
 			l.readChar()
 		}
 	}
 }
 
-// logToken escreve as informações do token gerado no arquivo de log.
+// takeDoc devolve o doc comment acumulado (linhas unidas por '\n') e limpa o acúmulo.
+func (l *Lexer) takeDoc() string {
+	if len(l.docLines) == 0 {
+		return ""
+	}
+	doc := strings.Join(l.docLines, "\n")
+	l.docLines = nil
+	return doc
+}
+
+// tracef envia uma mensagem ao tracer da fase do lexer.
+func (l *Lexer) tracef(format string, args ...interface{}) {
+	l.tracer.Tracef(trace.Lexer, format, args...)
+}
+
+// logToken registra no trace as informações do token gerado.
 func (l *Lexer) logToken(tok token.Token) {
-	// Não logar EOF para não poluir o final do log
+	// Não registra EOF para não poluir o final do trace
 	if tok.Type == token.EOF {
 		return
 	}
-	l.logger.Printf("Token gerado -> Tipo: %-10s | Literal: '%s'", tok.Type, tok.Literal)
+	l.tracef("Token gerado -> Tipo: %-10s | Literal: '%s' | Posição: %s", tok.Type, tok.Literal, tok.Pos)
 }
@@ -2,49 +2,73 @@
 package lexer
 
 import (
-	"log"
-	"os"
+	"strings"
 	"taquion/compiler/token"
+	"taquion/compiler/trace"
+	"unicode/utf8"
 )
 
 // Lexer realiza a análise lexical do código fonte.
 type Lexer struct {
 	input        string
-	position     int  // Posição atual no input (aponta para o caractere atual)
-	readPosition int  // Próxima posição de leitura no input (depois do caractere atual)
-	ch           byte // Caractere atual sob exame
+	file         string // Nome do arquivo fonte, usado nas posições dos tokens
+	position     int    // Posição atual no input, em bytes (aponta para o caractere atual)
+	readPosition int    // Próxima posição de leitura no input, em bytes (depois do caractere atual)
+	ch           rune   // Caractere atual sob exame
+	line         int    // Linha do caractere atual (começa em 1)
+	column       int    // Coluna do caractere atual, em runes (começa em 1)
+	errors       []string
+	docLines     []string // linhas de doc comment ('///') pendentes para o próximo token
+	interpDepth  []int    // profundidade de '{' em cada interpolação "${...}" aberta, da mais externa para a mais interna
+	insertSemi   bool     // o último token pode terminar uma instrução: a próxima quebra de linha vira ';'
+	tokenLine    int      // linha em que terminou o último token lido (0 antes do primeiro)
 
-	logger *log.Logger
+	tracer trace.Tracer
 }
 
-// New cria e inicializa um novo Lexer.
-func New(input string) *Lexer {
-	// Garante que o diretório 'log' exista para evitar erros.
-	if _, err := os.Stat("log"); os.IsNotExist(err) {
-		os.Mkdir("log", 0755)
-	}
-
-	// Abre o arquivo de log. O modo TRUNC apaga o conteúdo anterior a cada nova execução.
-	file, err := os.OpenFile("log/lexer.log", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
-	if err != nil {
-		log.Fatalf("Erro ao abrir o arquivo de log do lexer: %v", err)
-	}
+// New cria e inicializa um novo Lexer para um código fonte sem nome de arquivo.
+// tracer pode ser nil, o que desliga o rastreamento.
+func New(input string, tracer trace.Tracer) *Lexer {
+	return NewFile("", input, tracer)
+}
 
+// NewFile cria um Lexer cujos tokens carregam o nome do arquivo em suas posições.
+func NewFile(file, input string, tracer trace.Tracer) *Lexer {
 	l := &Lexer{
 		input:  input,
-		logger: log.New(file, "LEXER:  ", log.LstdFlags),
+		file:   file,
+		line:   1,
+		tracer: trace.OrNop(tracer),
 	}
 
-	l.logger.Println("Iniciando nova sessão de lexing.")
+	l.tracef("Iniciando nova sessão de lexing.")
 	l.readChar() // Inicializa o primeiro caractere
 	return l
 }
 
-// NextToken analisa o input e retorna o próximo token.
+// NextToken analisa o input e retorna o próximo token. Como em Go, um ';' é inserido
+// automaticamente na quebra de linha (ou no fim do arquivo) que segue um token capaz de
+// terminar uma instrução; veja endsStatement.
 func (l *Lexer) NextToken() token.Token {
+	if l.skipWhitespaceAndComments() {
+		pos := l.currentPosition()
+		tok := token.Token{Type: token.SEMICOLON, Literal: "\n", Pos: pos, End: pos}
+		l.insertSemi = false
+		l.logToken(tok)
+		return tok
+	}
+	tok := l.scanToken()
+	l.insertSemi = endsStatement(tok.Type)
+	l.tokenLine = tok.End.Line
+	return tok
+}
+
+// scanToken lê o token que começa no caractere atual.
+func (l *Lexer) scanToken() token.Token {
 	var tok token.Token
 
-	l.skipWhitespaceAndComments() // Lógica de pular espaços e comentários combinada
+	start := l.currentPosition()
+	doc := l.takeDoc()
 
 	switch l.ch {
 	case '=':
@@ -53,6 +77,8 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			literal := string(ch) + string(l.ch)
 			tok = token.Token{Type: token.EQ, Literal: literal}
+		} else if l.peekChar() == '>' {
+			tok = l.twoCharToken(token.ARROW)
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
@@ -74,19 +100,89 @@ func (l *Lexer) NextToken() token.Token {
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		switch l.peekChar() {
+		case '=':
+			tok = l.twoCharToken(token.PLUS_ASSIGN)
+		case '+':
+			tok = l.twoCharToken(token.INCREMENT)
+		default:
+			tok = newToken(token.PLUS, l.ch)
+		}
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		switch l.peekChar() {
+		case '=':
+			tok = l.twoCharToken(token.MINUS_ASSIGN)
+		case '-':
+			tok = l.twoCharToken(token.DECREMENT)
+		default:
+			tok = newToken(token.MINUS, l.ch)
+		}
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.ASTERISK_ASSIGN)
+		} else {
+			tok = newToken(token.ASTERISK, l.ch)
+		}
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.SLASH_ASSIGN)
+		} else {
+			tok = newToken(token.SLASH, l.ch)
+		}
 	case '%':
-		tok = newToken(token.MODULO, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.MODULO_ASSIGN)
+		} else {
+			tok = newToken(token.MODULO, l.ch)
+		}
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		switch {
+		case l.peekChar() == '<' && l.peekCharN(2) == '=':
+			tok = l.threeCharToken(token.SHL_ASSIGN)
+		case l.peekChar() == '<':
+			tok = l.twoCharToken(token.SHL)
+		case l.peekChar() == '=':
+			tok = l.twoCharToken(token.LT_EQ)
+		default:
+			tok = newToken(token.LT, l.ch)
+		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		switch {
+		case l.peekChar() == '>' && l.peekCharN(2) == '=':
+			tok = l.threeCharToken(token.SHR_ASSIGN)
+		case l.peekChar() == '>':
+			tok = l.twoCharToken(token.SHR)
+		case l.peekChar() == '=':
+			tok = l.twoCharToken(token.GT_EQ)
+		default:
+			tok = newToken(token.GT, l.ch)
+		}
+	case '&':
+		switch l.peekChar() {
+		case '&':
+			tok = l.twoCharToken(token.AND)
+		case '=':
+			tok = l.twoCharToken(token.AND_ASSIGN)
+		default:
+			tok = newToken(token.BIT_AND, l.ch)
+		}
+	case '|':
+		switch l.peekChar() {
+		case '|':
+			tok = l.twoCharToken(token.OR)
+		case '=':
+			tok = l.twoCharToken(token.OR_ASSIGN)
+		default:
+			tok = newToken(token.BIT_OR, l.ch)
+		}
+	case '^':
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.XOR_ASSIGN)
+		} else {
+			tok = newToken(token.BIT_XOR, l.ch)
+		}
+	case '~':
+		tok = newToken(token.BIT_NOT, l.ch)
 	case ':':
 		tok = newToken(token.COLON, l.ch)
 	case '.':
@@ -95,15 +191,42 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.DOLLAR, l.ch)
 	case '{':
 		tok = newToken(token.LBRACE, l.ch)
+		if n := len(l.interpDepth); n > 0 {
+			l.interpDepth[n-1]++
+		}
 	case '}':
 		tok = newToken(token.RBRACE, l.ch)
+		if n := len(l.interpDepth); n > 0 {
+			if l.interpDepth[n-1] > 0 {
+				l.interpDepth[n-1]--
+				break
+			}
+			// Fecha a expressão de "${...}" e continua lendo o restante da string.
+			l.interpDepth = l.interpDepth[:n-1]
+			literal, interp := l.readString()
+			tok = token.Token{Type: token.INTERP_END, Literal: literal}
+			if interp {
+				tok.Type = token.INTERP_MID
+				l.interpDepth = append(l.interpDepth, 0)
+			}
+		}
 	case '[':
 		tok = newToken(token.LBRACKET, l.ch)
 	case ']':
 		tok = newToken(token.RBRACKET, l.ch)
 	case '"':
+		literal, interp := l.readString()
+		tok = token.Token{Type: token.STRING, Literal: literal}
+		if interp {
+			tok.Type = token.INTERP_START
+			l.interpDepth = append(l.interpDepth, 0)
+		}
+	case '`':
 		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		tok.Literal = l.readRawString()
+	case '\'':
+		tok.Type = token.CHAR
+		tok.Literal = l.readCharLiteral()
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -111,11 +234,17 @@ func (l *Lexer) NextToken() token.Token {
 		if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
+			tok.Pos, tok.End, tok.Doc = start, l.currentPosition(), doc
 			l.logToken(tok)
 			return tok
 		} else if isDigit(l.ch) {
+			literal, isFloat := l.readNumber()
 			tok.Type = token.INT
-			tok.Literal = l.readNumber()
+			if isFloat {
+				tok.Type = token.FLOAT
+			}
+			tok.Literal = literal
+			tok.Pos, tok.End, tok.Doc = start, l.currentPosition(), doc
 			l.logToken(tok)
 			return tok
 		} else {
@@ -124,41 +253,290 @@ func (l *Lexer) NextToken() token.Token {
 	}
 
 	l.readChar()
+	tok.Pos, tok.End, tok.Doc = start, l.currentPosition(), doc
 	l.logToken(tok)
 	return tok
 }
 
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) || isDigit(l.ch) {
+	for isIdentifierPart(l.ch) {
 		l.readChar()
 	}
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+// readNumber lê um literal numérico e informa se ele é de ponto flutuante,
+// ou seja, se possui parte fracionária (1.5) ou expoente (1e9, 2.5E-3).
+// Inteiros aceitam os prefixos 0x, 0b e 0o e '_' como separador de dígitos (1_000_000).
+// O ponto só é consumido quando seguido de dígito, para não engolir acessos como `x.campo`.
+func (l *Lexer) readNumber() (string, bool) {
+	start := l.currentPosition()
 	position := l.position
-	hasDot := false
-	for isDigit(l.ch) || (l.ch == '.' && !hasDot) {
-		if l.ch == '.' {
-			hasDot = true
+
+	if l.ch == '0' {
+		if base, kind := numberBase(l.peekChar()); base != 10 {
+			l.readChar()
+			l.readChar() // consome o prefixo
+			digitsStart := l.position
+			for isIdentifierPart(l.ch) {
+				l.readChar()
+			}
+			l.validateDigits(start, l.input[digitsStart:l.position], base, kind)
+			return l.input[position:l.position], false
 		}
+	}
+
+	isFloat := false
+	l.readDecimalDigits()
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		isFloat = true
 		l.readChar()
+		l.readDecimalDigits()
+	}
+	if l.ch == 'e' || l.ch == 'E' {
+		next := l.peekChar()
+		if isDigit(next) || ((next == '+' || next == '-') && isDigit(l.peekCharN(2))) {
+			isFloat = true
+			l.readChar() // 'e'
+			if l.ch == '+' || l.ch == '-' {
+				l.readChar()
+			}
+			for isDigit(l.ch) {
+				l.readChar()
+			}
+		}
+	}
+	literal := l.input[position:l.position]
+	if strings.Contains(literal, "__") || strings.Contains(literal, "_.") || strings.HasSuffix(literal, "_") {
+		l.errorAt(start, "'_' deve separar dígitos no literal %q", literal)
+	}
+	if !isFloat && len(literal) > 1 && literal[0] == '0' {
+		// Como em Go, 017 seria octal; aqui o octal exige o prefixo 0o.
+		l.errorAt(start, "literal inteiro %q com zero à esquerda; use o prefixo 0o para octal", literal)
+	}
+	return literal, isFloat
+}
+
+// readDecimalDigits consome dígitos decimais e separadores '_'.
+func (l *Lexer) readDecimalDigits() {
+	for isDigit(l.ch) || l.ch == '_' {
+		l.readChar()
+	}
+}
+
+// numberBase identifica o prefixo de base que segue um '0' inicial.
+func numberBase(ch rune) (int, string) {
+	switch ch {
+	case 'x', 'X':
+		return 16, "hexadecimal"
+	case 'b', 'B':
+		return 2, "binário"
+	case 'o', 'O':
+		return 8, "octal"
+	default:
+		return 10, "decimal"
+	}
+}
+
+// validateDigits verifica os dígitos (após o prefixo) de um literal hexadecimal, binário ou octal.
+func (l *Lexer) validateDigits(pos token.Position, digits string, base int, kind string) {
+	if strings.Trim(digits, "_") == "" {
+		l.errorAt(pos, "literal %s sem dígitos", kind)
+		return
+	}
+	if strings.Contains(digits, "__") || strings.HasSuffix(digits, "_") {
+		l.errorAt(pos, "'_' deve separar dígitos no literal %s", kind)
+		return
+	}
+	for _, ch := range digits {
+		if ch == '_' {
+			continue
+		}
+		if !isHexDigit(ch) || hexValue(ch) >= base {
+			l.errorAt(pos, "dígito inválido %q em literal %s", ch, kind)
+			return
+		}
 	}
-	return l.input[position:l.position]
 }
 
-func (l *Lexer) readString() string {
-	position := l.position + 1
+// readString lê um trecho de literal de string e retorna seu conteúdo com as sequências de
+// escape já decodificadas. O trecho termina na aspa de fechamento ou no início de uma
+// interpolação "${", caso em que interp é verdadeiro e l.ch aponta para o '{'.
+// Caso contrário, l.ch aponta para a aspa de fechamento.
+func (l *Lexer) readString() (literal string, interp bool) {
+	start := l.currentPosition()
+	var out strings.Builder
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
-			break
+		switch l.ch {
+		case '"':
+			return out.String(), false
+		case '$':
+			if l.peekChar() != '{' {
+				out.WriteRune(l.ch)
+				continue
+			}
+			l.readChar()
+			return out.String(), true
+		case 0, '\n':
+			l.errorAt(start, "string não terminada")
+			return out.String(), false
+		case '\\':
+			l.readEscape(&out)
+		default:
+			out.WriteRune(l.ch)
 		}
 	}
-	return l.input[position:l.position]
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+// readRawString lê uma string bruta entre crases. Não há sequências de escape nem interpolação,
+// e quebras de linha fazem parte do conteúdo ('\r' é descartado, como em Go).
+// Ao final, l.ch aponta para a crase de fechamento.
+func (l *Lexer) readRawString() string {
+	start := l.currentPosition()
+	var out strings.Builder
+	for {
+		l.readChar()
+		switch l.ch {
+		case '`':
+			return out.String()
+		case 0:
+			l.errorAt(start, "string bruta não terminada")
+			return out.String()
+		case '\r':
+		default:
+			out.WriteRune(l.ch)
+		}
+	}
+}
+
+// readCharLiteral lê um literal de caractere entre aspas simples, como 'a' ou '\n', e retorna
+// seu conteúdo decodificado. Ao final, l.ch aponta para a aspa de fechamento.
+func (l *Lexer) readCharLiteral() string {
+	start := l.currentPosition()
+	var out strings.Builder
+	l.readChar()
+	switch l.ch {
+	case '\'':
+		l.errorAt(start, "literal de caractere vazio")
+		return ""
+	case 0, '\n':
+		l.errorAt(start, "literal de caractere não terminado")
+		return ""
+	case '\\':
+		l.readEscape(&out)
+	default:
+		out.WriteRune(l.ch)
+	}
+
+	if l.peekChar() != '\'' {
+		if l.peekChar() == 0 || l.peekChar() == '\n' {
+			l.errorAt(start, "literal de caractere não terminado")
+			return out.String()
+		}
+		l.errorAt(start, "literal de caractere deve conter um único caractere")
+		for l.peekChar() != '\'' && l.peekChar() != '\n' && l.peekChar() != 0 {
+			l.readChar()
+		}
+		if l.peekChar() != '\'' {
+			return out.String()
+		}
+	}
+	l.readChar()
+	return out.String()
+}
+
+// readEscape decodifica a sequência de escape iniciada pela barra invertida em l.ch
+// e escreve o resultado em out. Ao final, l.ch aponta para o último caractere da sequência.
+func (l *Lexer) readEscape(out *strings.Builder) {
+	pos := l.currentPosition()
+	next := l.peekChar()
+	if next == 0 || next == '\n' {
+		// Deixa o fim de linha/arquivo para readString reportar a string não terminada.
+		return
+	}
+	l.readChar()
+
+	switch l.ch {
+	case 'n':
+		out.WriteByte('\n')
+	case 't':
+		out.WriteByte('\t')
+	case 'r':
+		out.WriteByte('\r')
+	case '0':
+		out.WriteByte(0)
+	case 'a':
+		out.WriteByte('\a')
+	case 'b':
+		out.WriteByte('\b')
+	case 'f':
+		out.WriteByte('\f')
+	case 'v':
+		out.WriteByte('\v')
+	case '\\', '"', '\'', '$':
+		out.WriteRune(l.ch)
+	case 'x':
+		// \xNN: exatamente dois dígitos hexadecimais, produz um único byte.
+		value := 0
+		for i := 0; i < 2; i++ {
+			if !isHexDigit(l.peekChar()) {
+				l.errorAt(pos, "sequência de escape \\x inválida: esperava dois dígitos hexadecimais")
+				return
+			}
+			l.readChar()
+			value = value*16 + hexValue(l.ch)
+		}
+		out.WriteByte(byte(value))
+	case 'u':
+		// \u{X...}: de 1 a 6 dígitos hexadecimais com um code point Unicode, gravado em UTF-8.
+		if l.peekChar() != '{' {
+			l.errorAt(pos, "sequência de escape \\u inválida: esperava '{'")
+			return
+		}
+		l.readChar()
+		value, digits := 0, 0
+		for isHexDigit(l.peekChar()) {
+			l.readChar()
+			value = value*16 + hexValue(l.ch)
+			digits++
+			if digits > 6 {
+				break
+			}
+		}
+		if l.peekChar() != '}' || digits == 0 || digits > 6 {
+			l.errorAt(pos, "sequência de escape \\u inválida: esperava de 1 a 6 dígitos hexadecimais entre '{' e '}'")
+			return
+		}
+		l.readChar()
+		if value > utf8.MaxRune || (value >= 0xD800 && value <= 0xDFFF) {
+			l.errorAt(pos, "sequência de escape \\u inválida: U+%X não é um code point válido", value)
+			return
+		}
+		out.WriteRune(rune(value))
+	default:
+		l.errorAt(pos, "sequência de escape desconhecida: \\%c", l.ch)
+		out.WriteRune(l.ch)
+	}
+}
+
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
+
+// twoCharToken consome o próximo caractere e cria um token com o caractere atual e o seguinte (ex: "<=").
+func (l *Lexer) twoCharToken(tokenType token.TokenType) token.Token {
+	ch := l.ch
+	l.readChar()
+	return token.Token{Type: tokenType, Literal: string(ch) + string(l.ch)}
+}
+
+// threeCharToken consome os dois próximos caracteres e cria um token de três caracteres (ex: "<<=").
+func (l *Lexer) threeCharToken(tokenType token.TokenType) token.Token {
+	first := l.ch
+	l.readChar()
+	second := l.ch
+	l.readChar()
+	return token.Token{Type: tokenType, Literal: string(first) + string(second) + string(l.ch)}
+}
@@ -0,0 +1,239 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+
+	"taquion/compiler/token"
+)
+
+// scanAll lê todos os tokens do input, incluindo o EOF.
+func scanAll(input string) ([]token.Token, []string) {
+	l := New(input, nil)
+	var toks []token.Token
+	for {
+		tok := l.NextToken()
+		toks = append(toks, tok)
+		if tok.Type == token.EOF {
+			return toks, l.Errors()
+		}
+	}
+}
+
+func tokenTypes(toks []token.Token) []token.TokenType {
+	types := make([]token.TokenType, len(toks))
+	for i, tok := range toks {
+		types[i] = tok.Type
+	}
+	return types
+}
+
+func TestSemicolonInsertion(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []token.TokenType
+	}{
+		{"identificador", "x\ny", []token.TokenType{token.IDENT, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"inteiro", "1\nx", []token.TokenType{token.INT, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"float", "2.5\nx", []token.TokenType{token.FLOAT, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"string", "\"a\"\nx", []token.TokenType{token.STRING, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"caractere", "'a'\nx", []token.TokenType{token.CHAR, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"fecha parêntese", "f()\nx", []token.TokenType{token.IDENT, token.LPAREN, token.RPAREN, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"fecha colchete", "a[0]\nx", []token.TokenType{token.IDENT, token.LBRACKET, token.INT, token.RBRACKET, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"fecha chave", "{}\nx", []token.TokenType{token.LBRACE, token.RBRACE, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"incremento", "i++\nx", []token.TokenType{token.IDENT, token.INCREMENT, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"return", "return\nx", []token.TokenType{token.RETURN, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"operador binário", "a +\nb", []token.TokenType{token.IDENT, token.PLUS, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"operador lógico", "a &&\nb", []token.TokenType{token.IDENT, token.AND, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"abre chave", "{\nx\n}", []token.TokenType{token.LBRACE, token.IDENT, token.SEMICOLON, token.RBRACE, token.SEMICOLON, token.EOF}},
+		{"vírgula", "f(a,\nb)", []token.TokenType{token.IDENT, token.LPAREN, token.IDENT, token.COMMA, token.IDENT, token.RPAREN, token.SEMICOLON, token.EOF}},
+		{"';' explícito", "x;\ny", []token.TokenType{token.IDENT, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"linhas em branco", "x\n\n\ny", []token.TokenType{token.IDENT, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"comentário de linha", "x // fim\ny", []token.TokenType{token.IDENT, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"comentário de bloco em várias linhas", "x /* a\nb */ y", []token.TokenType{token.IDENT, token.SEMICOLON, token.IDENT, token.SEMICOLON, token.EOF}},
+		{"comentário de bloco na mesma linha", "x /* a */ + y", []token.TokenType{token.IDENT, token.PLUS, token.IDENT, token.SEMICOLON, token.EOF}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toks, errs := scanAll(tt.input)
+			if len(errs) > 0 {
+				t.Fatalf("erros inesperados: %v", errs)
+			}
+			got := tokenTypes(toks)
+			if len(got) != len(tt.want) {
+				t.Fatalf("tokens de %q = %v, esperava %v", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("tokens de %q = %v, esperava %v", tt.input, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input   string
+		typ     token.TokenType
+		literal string
+		err     string // trecho da mensagem de erro esperada, ou "" sem erro
+	}{
+		{"42", token.INT, "42", ""},
+		{"0", token.INT, "0", ""},
+		{"0x1F", token.INT, "0x1F", ""},
+		{"0XfF", token.INT, "0XfF", ""},
+		{"0b1010", token.INT, "0b1010", ""},
+		{"0o17", token.INT, "0o17", ""},
+		{"1_000_000", token.INT, "1_000_000", ""},
+		{"0xFF_FF", token.INT, "0xFF_FF", ""},
+		{"3.14", token.FLOAT, "3.14", ""},
+		{"1e10", token.FLOAT, "1e10", ""},
+		{"2.5e-3", token.FLOAT, "2.5e-3", ""},
+		{"0.5", token.FLOAT, "0.5", ""},
+		{"0x", token.INT, "0x", "1:1: literal hexadecimal sem dígitos"},
+		{"0b", token.INT, "0b", "1:1: literal binário sem dígitos"},
+		{"0b102", token.INT, "0b102", "1:1: dígito inválido '2' em literal binário"},
+		{"0o8", token.INT, "0o8", "1:1: dígito inválido '8' em literal octal"},
+		{"0xG", token.INT, "0xG", "1:1: dígito inválido 'G' em literal hexadecimal"},
+		{"0x1__0", token.INT, "0x1__0", "1:1: '_' deve separar dígitos no literal hexadecimal"},
+		{"1__0", token.INT, "1__0", "1:1: '_' deve separar dígitos no literal \"1__0\""},
+		{"1_", token.INT, "1_", "1:1: '_' deve separar dígitos no literal \"1_\""},
+		{"1_.5", token.FLOAT, "1_.5", "1:1: '_' deve separar dígitos no literal \"1_.5\""},
+		{"017", token.INT, "017", "1:1: literal inteiro \"017\" com zero à esquerda; use o prefixo 0o para octal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			toks, errs := scanAll(tt.input)
+			if toks[0].Type != tt.typ || toks[0].Literal != tt.literal {
+				t.Errorf("token = %s %q, esperava %s %q", toks[0].Type, toks[0].Literal, tt.typ, tt.literal)
+			}
+			checkErrors(t, errs, tt.err)
+		})
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		literal string
+		err     string
+	}{
+		{"simples", `"olá"`, "olá", ""},
+		{"escapes comuns", `"a\tb\nc\\d\"e"`, "a\tb\nc\\d\"e", ""},
+		{"escape hexadecimal", `"\x41\x7a"`, "Az", ""},
+		{"escape unicode", `"\u{E9}\u{1F600}"`, "é😀", ""},
+		{"cifrão escapado", `"\${x}"`, "${x}", ""},
+		{"escape desconhecido", `"a\qb"`, "aqb", `1:3: sequência de escape desconhecida: \q`},
+		{"hexadecimal curto", `"\x4"`, "", `1:2: sequência de escape \x inválida`},
+		{"unicode sem chaves", `"\u41"`, "41", `1:2: sequência de escape \u inválida: esperava '{'`},
+		{"surrogate", `"\u{D800}"`, "", `1:2: sequência de escape \u inválida: U+D800`},
+		{"não terminada", `"abc`, "abc", "1:1: string não terminada"},
+		{"quebra de linha", "\"abc\ndef", "abc", "1:1: string não terminada"},
+		{"bruta", "`a\\n${x}`", `a\n${x}`, ""},
+		{"bruta em várias linhas", "`linha 1\r\nlinha 2`", "linha 1\nlinha 2", ""},
+		{"bruta não terminada", "`abc", "abc", "1:1: string bruta não terminada"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toks, errs := scanAll(tt.input)
+			if toks[0].Type != token.STRING || toks[0].Literal != tt.literal {
+				t.Errorf("token = %s %q, esperava STRING %q", toks[0].Type, toks[0].Literal, tt.literal)
+			}
+			checkErrors(t, errs, tt.err)
+		})
+	}
+}
+
+func TestUnicodeIdentifierPositions(t *testing.T) {
+	toks, errs := scanAll("let ação = 1\nlet π = 2")
+	if len(errs) > 0 {
+		t.Fatalf("erros inesperados: %v", errs)
+	}
+	want := []struct {
+		typ                  token.TokenType
+		literal              string
+		line, column, offset int
+		endColumn            int
+	}{
+		{token.LET, "let", 1, 1, 0, 4},
+		{token.IDENT, "ação", 1, 5, 4, 9},
+		{token.ASSIGN, "=", 1, 10, 11, 11},
+		{token.INT, "1", 1, 12, 13, 13},
+		{token.SEMICOLON, "\n", 1, 13, 14, 13},
+		{token.LET, "let", 2, 1, 15, 4},
+		{token.IDENT, "π", 2, 5, 19, 6},
+		{token.ASSIGN, "=", 2, 7, 22, 8},
+	}
+	for i, w := range want {
+		tok := toks[i]
+		if tok.Type != w.typ || tok.Literal != w.literal {
+			t.Fatalf("token %d = %s %q, esperava %s %q", i, tok.Type, tok.Literal, w.typ, w.literal)
+		}
+		if tok.Pos.Line != w.line || tok.Pos.Column != w.column || tok.Pos.Offset != w.offset || tok.End.Column != w.endColumn {
+			t.Errorf("%q: início %d:%d (offset %d), fim na coluna %d; esperava %d:%d (offset %d), fim na coluna %d",
+				w.literal, tok.Pos.Line, tok.Pos.Column, tok.Pos.Offset, tok.End.Column, w.line, w.column, w.offset, w.endColumn)
+		}
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	input := `/// Primeira linha
+/// Segunda linha
+func f() {}
+let x = 1 /// depois de código, não é doc
+let y = 2
+/// separado por uma linha em branco
+
+let z = 3
+/// descartado por um comentário comum
+// comum
+let w = 4
+let v = 5 /// comentário
+/// doc de u
+let u = 6
+`
+	toks, errs := scanAll(input)
+	if len(errs) > 0 {
+		t.Fatalf("erros inesperados: %v", errs)
+	}
+	docs := map[string]string{}
+	for i, tok := range toks {
+		if tok.Type == token.FUNCTION || tok.Type == token.LET {
+			docs[toks[i+1].Literal] = tok.Doc
+		}
+		if tok.Type != token.FUNCTION && tok.Type != token.LET && tok.Doc != "" {
+			t.Errorf("doc %q anexado ao token %s %q", tok.Doc, tok.Type, tok.Literal)
+		}
+	}
+	want := map[string]string{
+		"f": "Primeira linha\nSegunda linha",
+		"x": "",
+		"y": "",
+		"z": "",
+		"w": "",
+		"v": "",
+		"u": "doc de u",
+	}
+	for name, doc := range want {
+		if docs[name] != doc {
+			t.Errorf("doc de %s = %q, esperava %q", name, docs[name], doc)
+		}
+	}
+}
+
+// checkErrors confere que houve exatamente um erro contendo want, ou nenhum quando want é "".
+func checkErrors(t *testing.T, errs []string, want string) {
+	t.Helper()
+	if want == "" {
+		if len(errs) > 0 {
+			t.Errorf("erros inesperados: %v", errs)
+		}
+		return
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0], want) {
+		t.Errorf("erros = %v, esperava um erro com %q", errs, want)
+	}
+}
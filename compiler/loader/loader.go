@@ -0,0 +1,203 @@
+// Package loader encontra, analisa e ordena os pacotes de um programa Taquion.
+//
+// Os imports são resolvidos em relação à raiz do módulo (o diretório do arquivo de entrada):
+// import "util/texto" carrega todos os arquivos .taq de <raiz>/util/texto, que devem declarar
+// 'package texto'.
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"taquion/compiler/ast"
+	"taquion/compiler/lexer"
+	"taquion/compiler/parser"
+	"taquion/compiler/token"
+	"taquion/compiler/trace"
+)
+
+// Extension é a extensão dos arquivos fonte.
+const Extension = ".taq"
+
+type loader struct {
+	root     string
+	tracer   trace.Tracer
+	packages map[string]*ast.Package
+	loading  map[string]bool // pacotes cujos imports ainda estão sendo carregados
+	stack    []string        // caminho de imports até o pacote atual, para relatar ciclos
+	order    []*ast.Package
+	errors   []string
+}
+
+// Load carrega o pacote principal e, recursivamente, os pacotes que ele importa. entry pode ser
+// um arquivo (o pacote principal é só esse arquivo) ou um diretório (todos os seus arquivos .taq).
+// Os pacotes voltam em ordem de dependência: cada pacote aparece depois dos que ele importa e o
+// principal é o último. Com erros, a lista de pacotes não deve ser usada.
+func Load(entry string, tracer trace.Tracer) ([]*ast.Package, []string) {
+	l := &loader{
+		tracer:   trace.OrNop(tracer),
+		packages: make(map[string]*ast.Package),
+		loading:  make(map[string]bool),
+	}
+
+	info, err := os.Stat(entry)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("erro ao ler %s: %s", entry, err)}
+	}
+	var files []string
+	if info.IsDir() {
+		l.root = entry
+		if files, err = sourceFiles(entry); err != nil {
+			return nil, []string{err.Error()}
+		}
+	} else {
+		l.root = filepath.Dir(entry)
+		files = []string{entry}
+	}
+
+	main := &ast.Package{Path: "", Name: "main"}
+	l.loadPackage(main, files)
+	return l.order, l.errors
+}
+
+// loadPackage analisa os arquivos de pkg, carrega os pacotes importados e só então acrescenta
+// pkg à ordem de geração.
+func (l *loader) loadPackage(pkg *ast.Package, files []string) {
+	l.packages[pkg.Path] = pkg
+	l.loading[pkg.Path] = true
+	l.stack = append(l.stack, pkg.Path)
+	defer func() {
+		l.stack = l.stack[:len(l.stack)-1]
+		delete(l.loading, pkg.Path)
+	}()
+
+	for _, file := range files {
+		program := l.parseFile(file)
+		if program == nil {
+			continue
+		}
+		l.checkPackageName(pkg, program, file)
+		pkg.Files = append(pkg.Files, program)
+	}
+
+	for _, program := range pkg.Files {
+		for _, stmt := range program.Statements {
+			if imp, ok := stmt.(*ast.ImportStatement); ok {
+				l.importPackage(imp)
+			}
+		}
+	}
+	l.order = append(l.order, pkg)
+}
+
+// importPackage resolve um import e carrega o pacote na primeira vez em que ele aparece.
+func (l *loader) importPackage(imp *ast.ImportStatement) {
+	importPath := imp.Path.Value
+	if importPath == "" {
+		return // o parser já relatou o caminho vazio
+	}
+	if path.IsAbs(importPath) || path.Clean(importPath) != importPath || strings.HasPrefix(importPath, "..") {
+		l.errorAt(imp.Path.Pos(), "caminho de importação inválido: %q", importPath)
+		return
+	}
+	if l.loading[importPath] {
+		l.errorAt(imp.Path.Pos(), "ciclo de importação: %s", l.cycle(importPath))
+		return
+	}
+	if _, done := l.packages[importPath]; done {
+		return
+	}
+
+	dir := filepath.Join(l.root, filepath.FromSlash(importPath))
+	files, err := sourceFiles(dir)
+	if err != nil {
+		l.errorAt(imp.Path.Pos(), "pacote não encontrado: %q (procurado em %s)", importPath, dir)
+		return
+	}
+	l.tracer.Tracef(trace.Parser, "Carregando pacote %q de %s", importPath, dir)
+	pkg := &ast.Package{Path: importPath, Name: imp.PackageName()}
+	l.loadPackage(pkg, files)
+}
+
+// cycle descreve o ciclo que se fecha ao importar importPath, ex: "main -> a -> b -> a".
+func (l *loader) cycle(importPath string) string {
+	start := 0
+	for i, p := range l.stack {
+		if p == importPath {
+			start = i
+		}
+	}
+	names := []string{}
+	for _, p := range l.stack[start:] {
+		names = append(names, displayPath(p))
+	}
+	return strings.Join(append(names, displayPath(importPath)), " -> ")
+}
+
+// checkPackageName confere que o arquivo declara o pacote esperado. O pacote principal aceita
+// arquivos sem 'package'; os importados precisam declarar o nome do seu diretório.
+func (l *loader) checkPackageName(pkg *ast.Package, program *ast.Program, file string) {
+	for _, stmt := range program.Statements {
+		decl, ok := stmt.(*ast.PackageStatement)
+		if !ok {
+			continue
+		}
+		if decl.Name.Value != pkg.Name {
+			l.errorAt(decl.Name.Pos(), "o arquivo declara 'package %s', mas pertence ao pacote %s", decl.Name.Value, pkg.Name)
+		}
+		return
+	}
+	if pkg.Path != "" {
+		l.errors = append(l.errors, fmt.Sprintf("%s: falta a declaração 'package %s'", file, pkg.Name))
+	}
+}
+
+// parseFile lê e analisa um arquivo fonte. Devolve nil quando o arquivo não pôde ser lido ou tem
+// erros de sintaxe, que são acumulados em l.errors.
+func (l *loader) parseFile(file string) *ast.Program {
+	source, err := os.ReadFile(file)
+	if err != nil {
+		l.errors = append(l.errors, fmt.Sprintf("erro ao ler o arquivo %s: %s", file, err))
+		return nil
+	}
+	p := parser.New(lexer.NewFile(file, string(source), l.tracer), l.tracer)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		l.errors = append(l.errors, p.Errors()...)
+		return nil
+	}
+	return program
+}
+
+func (l *loader) errorAt(pos token.Position, format string, args ...interface{}) {
+	l.errors = append(l.errors, fmt.Sprintf("%s: %s", pos, fmt.Sprintf(format, args...)))
+}
+
+// sourceFiles lista os arquivos .taq de um diretório, em ordem alfabética.
+func sourceFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), Extension) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("nenhum arquivo %s em %s", Extension, dir)
+	}
+	return files, nil
+}
+
+// displayPath nomeia um pacote em mensagens: o pacote principal não tem caminho de importação.
+func displayPath(importPath string) string {
+	if importPath == "" {
+		return "main"
+	}
+	return importPath
+}
@@ -2,20 +2,28 @@
 package parser
 
 import (
-	"fmt"
+	"errors"
 	"strconv"
+	"strings"
 	"taquion/compiler/ast"
 	"taquion/compiler/token"
+	"unicode/utf8"
 )
 
 func (p *Parser) parseExpression(precedence int) ast.Expression {
-	logger.Printf("        >> parseExpression (precedência: %d)", precedence)
-	defer logger.Printf("        << parseExpression (precedência: %d)", precedence)
+	p.tracef("        >> parseExpression (precedência: %d)", precedence)
+	defer p.tracef("        << parseExpression (precedência: %d)", precedence)
 
 	prefix := p.prefixParseFns[p.curToken.Type]
 	if prefix == nil {
 		p.noPrefixParseFnError(p.curToken.Type)
-		return nil
+		bad := &ast.BadExpr{From: p.curToken, To: p.curToken}
+		// Um '}' ou o fim do arquivo no lugar da expressão pertencem a quem está em volta
+		// (o bloco, o programa): devolve o token para não desalinhar a recuperação.
+		if p.curTokenIs(token.RBRACE) || p.curTokenIs(token.EOF) {
+			p.backup()
+		}
+		return bad
 	}
 	leftExp := prefix()
 
@@ -32,7 +40,7 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 
 func (p *Parser) parseIdentifier() ast.Expression {
 	// Se o próximo token for um abre chaves, isso é um literal de struct
-	if p.peekTokenIs(token.LBRACE) {
+	if p.peekTokenIs(token.LBRACE) && !p.noCompositeLit {
 		typeName := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 		p.nextToken() // consome o nome do tipo para que curToken seja '{'
 		return p.parseCompositeLiteral(typeName)
@@ -44,11 +52,47 @@ func (p *Parser) parseIdentifier() ast.Expression {
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	literal := p.curToken.Literal
+	var value uint64
+	var err error
+	if hasBasePrefix(literal) {
+		value, err = strconv.ParseUint(literal, 0, 64)
+	} else {
+		// Decimais são lidos na base 10: com base 0, um '0' inicial seria tomado como octal.
+		value, err = strconv.ParseUint(strings.ReplaceAll(literal, "_", ""), 10, 64)
+	}
 	if err != nil {
-		msg := fmt.Sprintf("não foi possível analisar %q como inteiro", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
-		return nil
+		switch {
+		case errors.Is(err, strconv.ErrRange):
+			p.errorAt(p.curToken.Pos, "literal inteiro %s fora do intervalo de 64 bits", literal)
+		case !p.hasErrorAt(p.curToken.Pos):
+			// Erros de sintaxe no literal normalmente já foram reportados pelo lexer.
+			p.errorAt(p.curToken.Pos, "literal inteiro inválido: %s", literal)
+		}
+		return p.badExpr(lit.Token)
+	}
+	lit.Value = value
+	return lit
+}
+
+// hasBasePrefix informa se o literal inteiro tem prefixo de base (0x, 0b ou 0o).
+func hasBasePrefix(literal string) bool {
+	if len(literal) < 2 || literal[0] != '0' {
+		return false
+	}
+	switch literal[1] {
+	case 'x', 'X', 'b', 'B', 'o', 'O':
+		return true
+	}
+	return false
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		p.errorAt(p.curToken.Pos, "não foi possível analisar %q como número de ponto flutuante", p.curToken.Literal)
+		return p.badExpr(lit.Token)
 	}
 	lit.Value = value
 	return lit
@@ -58,6 +102,51 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+func (p *Parser) parseCharLiteral() ast.Expression {
+	lit := &ast.CharLiteral{Token: p.curToken}
+	// Um único byte (ex: '\xFF') vale por si só; caso contrário o conteúdo é um caractere UTF-8.
+	if len(p.curToken.Literal) == 1 {
+		lit.Value = rune(p.curToken.Literal[0])
+	} else if p.curToken.Literal != "" {
+		lit.Value, _ = utf8.DecodeRuneInString(p.curToken.Literal)
+	}
+	return lit
+}
+
+// parseInterpolation analisa uma string com interpolação. O lexer já a entrega dividida em
+// INTERP_START, as expressões embutidas, INTERP_MID entre elas e INTERP_END no final.
+func (p *Parser) parseInterpolation() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.curToken}
+	p.appendInterpolationText(str, p.curToken)
+
+	for {
+		p.nextToken()
+		part := p.parseExpression(LOWEST)
+		str.Parts = append(str.Parts, part)
+
+		switch {
+		case p.peekTokenIs(token.INTERP_MID):
+			p.nextToken()
+			p.appendInterpolationText(str, p.curToken)
+		case p.peekTokenIs(token.INTERP_END):
+			p.nextToken()
+			p.appendInterpolationText(str, p.curToken)
+			str.Close = p.curToken
+			return str
+		default:
+			p.errorAt(p.peekToken.Pos, "esperava '}' para fechar a interpolação, mas obteve %q", p.peekToken.Literal)
+			return p.badExpr(str.Token)
+		}
+	}
+}
+
+// appendInterpolationText acrescenta o trecho de texto do token, se não for vazio.
+func (p *Parser) appendInterpolationText(str *ast.InterpolatedString, tok token.Token) {
+	if tok.Literal != "" {
+		str.Parts = append(str.Parts, &ast.StringLiteral{Token: tok, Value: tok.Literal})
+	}
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.BooleanLiteral{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
@@ -70,16 +159,19 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	lparen := p.curToken
 	p.nextToken()
+	defer func(saved bool) { p.noCompositeLit = saved }(p.noCompositeLit)
+	p.noCompositeLit = false
 	exp := p.parseExpression(LOWEST)
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return p.badExpr(lparen)
 	}
 	return exp
 }
 
 // parseIfExpression analisa uma expressão 'if'.
-// A sintaxe esperada é: if <condição> <consequência> [else <alternativa>]
+// A sintaxe esperada é: if <condição> <consequência> [else <alternativa> | else if ...]
 func (p *Parser) parseIfExpression() ast.Expression {
 	expression := &ast.IfExpression{Token: p.curToken}
 
@@ -87,11 +179,14 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	p.nextToken()
 
 	// Analisa a expressão da condição.
+	saved := p.noCompositeLit
+	p.noCompositeLit = true
 	expression.Condition = p.parseExpression(LOWEST)
+	p.noCompositeLit = saved
 
 	// Após a condição, espera-se um abre chaves '{' para o bloco de consequência.
 	if !p.expectPeek(token.LBRACE) {
-		return nil // Retorna nulo se não encontrar o '{'
+		return p.badExpr(expression.Token)
 	}
 
 	// Analisa o bloco de código da consequência.
@@ -102,9 +197,24 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken() // Consome o token 'else'
 
+		// 'else if' vira uma alternativa cujo bloco contém apenas o 'if' seguinte.
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			ifTok := p.curToken
+			elseIf := p.parseIfExpression()
+			if _, bad := elseIf.(*ast.BadExpr); bad {
+				return p.badExpr(expression.Token)
+			}
+			expression.Alternative = &ast.BlockStatement{
+				Token:      ifTok,
+				Statements: []ast.Statement{&ast.ExpressionStatement{Token: ifTok, Expression: elseIf}},
+			}
+			return expression
+		}
+
 		// Espera-se um abre chaves '{' para o bloco de alternativa.
 		if !p.expectPeek(token.LBRACE) {
-			return nil // Retorna nulo se não encontrar o '{'
+			return p.badExpr(expression.Token)
 		}
 
 		// Analisa o bloco de código da alternativa.
@@ -117,11 +227,15 @@ func (p *Parser) parseIfExpression() ast.Expression {
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	lit := &ast.FunctionLiteral{Token: p.curToken}
 	if !p.expectPeek(token.LPAREN) {
-		return nil
+		return p.badExpr(lit.Token)
 	}
 	lit.Parameters = p.parseFunctionParameters()
-	if !p.expectPeek(token.LBRACE) {
-		return nil
+	if lit.Parameters == nil {
+		return p.badExpr(lit.Token)
+	}
+	var ok bool
+	if lit.ReturnType, ok = p.parseReturnType(); !ok || !p.expectPeek(token.LBRACE) {
+		return p.badExpr(lit.Token)
 	}
 	lit.Body = p.parseBlockStatement()
 	return lit
@@ -130,6 +244,10 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.curToken}
 	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if array.Elements == nil {
+		return p.badExpr(array.Token)
+	}
+	array.RBracket = p.curToken
 	return array
 }
 
@@ -146,15 +264,23 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 }
 
 func (p *Parser) parseAssignmentExpression(left ast.Expression) ast.Expression {
-	expr := &ast.AssignmentExpression{Token: p.curToken, Left: left}
+	expr := &ast.AssignmentExpression{Token: p.curToken, Operator: p.curToken.Literal, Left: left}
 	p.nextToken()
 	expr.Value = p.parseExpression(LOWEST)
 	return expr
 }
 
+func (p *Parser) parsePostfixExpression(left ast.Expression) ast.Expression {
+	return &ast.PostfixExpression{Token: p.curToken, Left: left, Operator: p.curToken.Literal}
+}
+
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
 	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	if exp.Arguments == nil {
+		return p.badExpr(exp.Token)
+	}
+	exp.RParen = p.curToken
 	return exp
 }
 
@@ -163,8 +289,9 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	p.nextToken()
 	exp.Index = p.parseExpression(LOWEST)
 	if !p.expectPeek(token.RBRACKET) {
-		return nil
+		return p.badExpr(exp.Token)
 	}
+	exp.RBracket = p.curToken
 	return exp
 }
 
@@ -172,7 +299,7 @@ func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
 	exp := &ast.MemberExpression{Token: p.curToken, Object: left}
 
 	if !p.expectPeek(token.IDENT) {
-		return nil
+		return p.badExpr(exp.Token)
 	}
 
 	exp.Property = &ast.Identifier{
@@ -200,8 +327,8 @@ func (p *Parser) parseCompositeLiteral(typeName *ast.Identifier) ast.Expression
 		if p.peekTokenIs(token.COLON) || p.peekTokenIs(token.ASSIGN) {
 			p.nextToken() // consome ':' ou '='
 		} else {
-			p.errors = append(p.errors, fmt.Sprintf("esperava ':' ou '=', mas obteve %q", p.peekToken.Literal))
-			return nil
+			p.errorAt(p.peekToken.Pos, "esperava ':' ou '=', mas obteve %q", p.peekToken.Literal)
+			return p.badExpr(lit.Token)
 		}
 
 		p.nextToken() // avança para o valor
@@ -219,7 +346,8 @@ func (p *Parser) parseCompositeLiteral(typeName *ast.Identifier) ast.Expression
 	}
 
 	if !p.expectPeek(token.RBRACE) {
-		return nil
+		return p.badExpr(lit.Token)
 	}
+	lit.RBrace = p.curToken
 	return lit
 }
@@ -3,8 +3,10 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 	"taquion/compiler/ast"
 	"taquion/compiler/token"
+	"taquion/compiler/trace"
 )
 
 func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
@@ -20,12 +22,30 @@ func (p *Parser) Errors() []string {
 }
 
 func (p *Parser) nextToken() {
+	p.prevToken = p.curToken
 	p.curToken = p.peekToken
-	p.peekToken = p.l.NextToken()
-	logger.Printf("Avançando token: cur=%-10s ('%s') | peek=%-10s ('%s')",
+	if n := len(p.pending); n > 0 {
+		p.peekToken = p.pending[n-1]
+		p.pending = p.pending[:n-1]
+	} else {
+		p.peekToken = p.l.NextToken()
+	}
+	if lexErrs := p.l.Errors(); len(lexErrs) > p.lexerErrors {
+		p.errors = append(p.errors, lexErrs[p.lexerErrors:]...)
+		p.lexerErrors = len(lexErrs)
+	}
+	p.tracef("Avançando token: cur=%-10s ('%s') | peek=%-10s ('%s')",
 		p.curToken.Type, p.curToken.Literal, p.peekToken.Type, p.peekToken.Literal)
 }
 
+// backup desfaz o último nextToken, devolvendo curToken para ser lido de novo.
+// Só é possível voltar um token.
+func (p *Parser) backup() {
+	p.pending = append(p.pending, p.peekToken)
+	p.peekToken = p.curToken
+	p.curToken = p.prevToken
+}
+
 func (p *Parser) curTokenIs(t token.TokenType) bool {
 	return p.curToken.Type == t
 }
@@ -43,15 +63,39 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 	return false
 }
 
+// tracef envia uma mensagem ao tracer da fase do parser.
+func (p *Parser) tracef(format string, args ...interface{}) {
+	p.tracer.Tracef(trace.Parser, format, args...)
+}
+
+// errorAt registra um erro de parsing prefixado pela posição no código fonte. Só o primeiro
+// erro de cada posição é registrado: os seguintes costumam ser consequência dele.
+func (p *Parser) errorAt(pos token.Position, format string, args ...interface{}) {
+	if p.hasErrorAt(pos) {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	p.errors = append(p.errors, fmt.Sprintf("%s: %s", pos, msg))
+}
+
+// hasErrorAt informa se algum erro já foi reportado na posição pos.
+func (p *Parser) hasErrorAt(pos token.Position) bool {
+	prefix := fmt.Sprintf("%s: ", pos)
+	for _, e := range p.errors {
+		if strings.HasPrefix(e, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("esperava o próximo token ser %s, mas obteve %s (%q)",
+	p.errorAt(p.peekToken.Pos, "esperava o próximo token ser %s, mas obteve %s (%q)",
 		t, p.peekToken.Type, p.peekToken.Literal)
-	p.errors = append(p.errors, msg)
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("nenhuma função de parsing de prefixo encontrada para %s", t)
-	p.errors = append(p.errors, msg)
+	p.errorAt(p.curToken.Pos, "nenhuma função de parsing de prefixo encontrada para %s (%q)", t, p.curToken.Literal)
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -86,6 +130,9 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
+		if p.peekTokenIs(token.RPAREN) {
+			break // vírgula final
+		}
 		p.nextToken()
 		ident := p.parseParameter()
 		if ident == nil {
@@ -97,22 +144,170 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
+
+	// Como em Go, 'a, b int' dá a 'a' o tipo do próximo parâmetro anotado.
+	for i := len(identifiers) - 2; i >= 0; i-- {
+		if identifiers[i].Type == nil {
+			identifiers[i].Type = identifiers[i+1].Type
+		}
+	}
 	return identifiers
 }
 
+// parseParameter analisa um parâmetro com o tipo anotado como 'x: T' ou 'x T', ou sem tipo
+// quando o parâmetro faz parte de um grupo ('a, b int').
 func (p *Parser) parseParameter() *ast.Identifier {
+	if !p.curTokenIs(token.IDENT) {
+		p.errorAt(p.curToken.Pos, "esperava o nome de um parâmetro, mas obteve %q", p.curToken.Literal)
+		return nil
+	}
 	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
-	// Checa se tem anotação de tipo: ex: x: int
-	if p.peekTokenIs(token.COLON) {
-		p.nextToken() // consome ':'
-		p.nextToken() // vai pro tipo
-		ident.Type = &ast.Identifier{
-			Token: p.curToken,
-			Value: p.curToken.Literal,
+	switch {
+	case p.peekTokenIs(token.COLON):
+		p.nextToken()
+	case p.peekTokenIs(token.COMMA), p.peekTokenIs(token.RPAREN):
+		return ident
+	}
+	p.nextToken()
+	if typ := p.parseType(); typ != nil {
+		ident.Type = typ
+		return ident
+	}
+	return nil
+}
+
+// parseReturnType analisa o tipo de retorno opcional entre ')' e '{'.
+// Devolve nil sem erro quando a função não declara tipo de retorno.
+func (p *Parser) parseReturnType() (ast.Expression, bool) {
+	if p.peekTokenIs(token.LBRACE) {
+		return nil, true
+	}
+	p.nextToken()
+	if p.curTokenIs(token.LPAREN) {
+		typ := p.parseTupleType()
+		return typ, typ != nil
+	}
+	typ := p.parseType()
+	return typ, typ != nil
+}
+
+// parseTupleType analisa a lista de tipos de retorno de uma função com múltiplos valores:
+// '(int, int)'. Um único tipo entre parênteses é o próprio tipo.
+func (p *Parser) parseTupleType() ast.Expression {
+	tuple := &ast.TupleType{Token: p.curToken}
+	for {
+		p.nextToken()
+		typ := p.parseType()
+		if typ == nil {
+			return nil
+		}
+		tuple.Types = append(tuple.Types, typ)
+		if !p.peekTokenIs(token.COMMA) {
+			break
 		}
+		p.nextToken()
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	tuple.RParen = p.curToken
+	if len(tuple.Types) == 1 {
+		return tuple.Types[0]
+	}
+	return tuple
+}
+
+// parseTypeAnnotation analisa o tipo opcional de uma declaração, escrito como ': T' ou
+// apenas 'T' após o nome. Devolve nil sem erro quando não há anotação.
+func (p *Parser) parseTypeAnnotation() (ast.Expression, bool) {
+	switch {
+	case p.peekTokenIs(token.COLON):
+		p.nextToken()
+	case p.peekTokenIs(token.ASSIGN), p.peekTokenIs(token.SEMICOLON),
+		p.peekTokenIs(token.RBRACE), p.peekTokenIs(token.EOF):
+		return nil, true
 	}
-	return ident
+	p.nextToken()
+	typ := p.parseType()
+	return typ, typ != nil
+}
+
+// parseType analisa uma expressão de tipo a partir de curToken: um nome de tipo, um
+// array de tamanho fixo '[N]T' ou um tipo de função 'func(T, ...) R'.
+func (p *Parser) parseType() ast.Expression {
+	switch p.curToken.Type {
+	case token.IDENT:
+		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if !p.peekTokenIs(token.DOT) {
+			return ident
+		}
+		// Tipo de um pacote importado: geo.Ponto.
+		p.nextToken()
+		qualified := &ast.MemberExpression{Token: p.curToken, Object: ident}
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		qualified.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		return qualified
+	case token.FUNCTION:
+		return p.parseFuncType()
+	case token.LBRACKET:
+		arr := &ast.ArrayType{Token: p.curToken}
+		if !p.expectPeek(token.INT) {
+			return nil
+		}
+		arr.Len = p.parseIntegerLiteral()
+		if !p.expectPeek(token.RBRACKET) {
+			return nil
+		}
+		p.nextToken()
+		if arr.Elem = p.parseType(); arr.Elem == nil {
+			return nil
+		}
+		return arr
+	default:
+		p.errorAt(p.curToken.Pos, "esperava um tipo, mas obteve %q", p.curToken.Literal)
+		return nil
+	}
+}
+
+// parseFuncType analisa 'func(T, ...) R'. O tipo de retorno é opcional e só é lido quando o
+// próximo token pode iniciar um tipo.
+func (p *Parser) parseFuncType() ast.Expression {
+	ft := &ast.FuncType{Token: p.curToken}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	for !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		param := p.parseType()
+		if param == nil {
+			return nil
+		}
+		ft.Params = append(ft.Params, param)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	ft.RParen = p.curToken
+	switch {
+	case p.peekTokenIs(token.LPAREN):
+		p.nextToken()
+		if ft.ReturnType = p.parseTupleType(); ft.ReturnType == nil {
+			return nil
+		}
+	case p.peekTokenIs(token.IDENT), p.peekTokenIs(token.LBRACKET), p.peekTokenIs(token.FUNCTION):
+		p.nextToken()
+		if ft.ReturnType = p.parseType(); ft.ReturnType == nil {
+			return nil
+		}
+	}
+	return ft
 }
 
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
@@ -125,6 +320,9 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	list = append(list, p.parseExpression(LOWEST))
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
+		if p.peekTokenIs(end) {
+			break // vírgula final, comum em listas que ocupam várias linhas
+		}
 		p.nextToken()
 		list = append(list, p.parseExpression(LOWEST))
 	}
@@ -133,3 +331,80 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	}
 	return list
 }
+
+// syncKeywords são as palavras-chave que iniciam uma instrução e servem de ponto de
+// sincronização depois de um erro.
+var syncKeywords = map[token.TokenType]bool{
+	token.LET:      true,
+	token.CONST:    true,
+	token.RETURN:   true,
+	token.FUNCTION: true,
+	token.TYPE:     true,
+	token.FOR:      true,
+	token.WHILE:    true,
+	token.MATCH:    true,
+	token.BREAK:    true,
+	token.CONTINUE: true,
+	token.PACKAGE:  true,
+	token.IMPORT:   true,
+}
+
+// synchronize descarta tokens depois de um erro até um ponto seguro para retomar a análise:
+// consome o próximo ';' ou para antes de um '}', do fim do arquivo ou de uma palavra-chave que
+// inicia uma instrução. Blocos '{ ... }' abertos durante o descarte são pulados por inteiro.
+func (p *Parser) synchronize() {
+	depth := 0
+	for !p.peekTokenIs(token.EOF) {
+		switch {
+		case p.peekTokenIs(token.LBRACE):
+			depth++
+		case p.peekTokenIs(token.RBRACE):
+			if depth == 0 {
+				return
+			}
+			depth--
+		case depth > 0:
+		case p.peekTokenIs(token.SEMICOLON):
+			p.nextToken()
+			return
+		case syncKeywords[p.peekToken.Type]:
+			return
+		}
+		p.nextToken()
+	}
+}
+
+// skipMember descarta o resto de um membro malformado no corpo de um tipo. Para com o próximo
+// token sendo o ';' que termina o membro ou o '}' que fecha o corpo; blocos abertos no caminho
+// (como o corpo de um método, inclusive quando o '{' é o token atual) são pulados por inteiro.
+func (p *Parser) skipMember() {
+	depth := 0
+	if p.curTokenIs(token.LBRACE) {
+		depth = 1
+	}
+	for !p.peekTokenIs(token.EOF) {
+		switch {
+		case p.peekTokenIs(token.LBRACE):
+			depth++
+		case p.peekTokenIs(token.RBRACE):
+			if depth == 0 {
+				return
+			}
+			depth--
+		case depth == 0 && p.peekTokenIs(token.SEMICOLON):
+			return
+		}
+		p.nextToken()
+	}
+}
+
+// badExpr devolve um ast.BadExpr que cobre de from até o token atual.
+func (p *Parser) badExpr(from token.Token) *ast.BadExpr {
+	return &ast.BadExpr{From: from, To: p.curToken}
+}
+
+// badStmt sincroniza o parser e devolve um ast.BadStmt que cobre os tokens descartados.
+func (p *Parser) badStmt(from token.Token) *ast.BadStmt {
+	p.synchronize()
+	return &ast.BadStmt{From: from, To: p.curToken}
+}
@@ -2,69 +2,65 @@
 package parser
 
 import (
-	"log"
-	"os"
-	"sync"
 	"taquion/compiler/ast"
 	"taquion/compiler/lexer"
 	"taquion/compiler/token"
+	"taquion/compiler/trace"
 )
 
-var (
-	logger   *log.Logger
-	logFile  *os.File
-	initOnce sync.Once
-)
-
-func initLogger() {
-	initOnce.Do(func() {
-		if err := os.MkdirAll("log", 0755); err != nil {
-			log.Fatalf("Erro ao criar diretório de log: %v", err)
-		}
-		var err error
-		logFile, err = os.OpenFile("log/parser.log", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
-		if err != nil {
-			log.Fatalf("Erro ao abrir o arquivo de log parser.log: %v", err)
-		}
-		logger = log.New(logFile, "PARSER: ", log.LstdFlags)
-		logger.Println("Iniciando nova sessão de parsing.")
-	})
-}
-
-func CloseLogger() {
-	if logFile != nil {
-		logFile.Close()
-	}
-}
-
 // Níveis de Precedência dos Operadores
 const (
 	_ int = iota
 	LOWEST
-	ASSIGN      // =
+	ASSIGN      // = += -= *= /= %=
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
 	EQUALS      // ==
-	LESSGREATER // > ou <
-	SUM         // +
-	PRODUCT     // * ou / ou %  <-- Adicionei aqui
+	LESSGREATER // > ou < ou >= ou <=
+	SUM         // + ou - ou | ou ^
+	PRODUCT     // * ou / ou % ou & ou << ou >>
 	PREFIX      // -X ou !X
 	CALL        // minhaFuncao(X)
 	INDEX       // array[index]
+	POSTFIX     // x++ ou x--
 )
 
 var precedences = map[token.TokenType]int{
+	token.OR:       LOGICAL_OR,
+	token.AND:      LOGICAL_AND,
 	token.EQ:       EQUALS,
 	token.NOT_EQ:   EQUALS,
 	token.LT:       LESSGREATER,
 	token.GT:       LESSGREATER,
+	token.LT_EQ:    LESSGREATER,
+	token.GT_EQ:    LESSGREATER,
 	token.PLUS:     SUM,
 	token.MINUS:    SUM,
 	token.SLASH:    PRODUCT,
 	token.ASTERISK: PRODUCT,
 	token.MODULO:   PRODUCT,
-	token.LPAREN:   CALL,
-	token.DOT:      CALL,
-	token.ASSIGN:   ASSIGN,
-	token.LBRACKET: INDEX,
+	// Operadores bit a bit seguem os níveis do Go: | e ^ somam, & e deslocamentos multiplicam.
+	token.BIT_OR:          SUM,
+	token.BIT_XOR:         SUM,
+	token.BIT_AND:         PRODUCT,
+	token.SHL:             PRODUCT,
+	token.SHR:             PRODUCT,
+	token.LPAREN:          CALL,
+	token.DOT:             CALL,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.MODULO_ASSIGN:   ASSIGN,
+	token.AND_ASSIGN:      ASSIGN,
+	token.OR_ASSIGN:       ASSIGN,
+	token.XOR_ASSIGN:      ASSIGN,
+	token.SHL_ASSIGN:      ASSIGN,
+	token.SHR_ASSIGN:      ASSIGN,
+	token.LBRACKET:        INDEX,
+	token.INCREMENT:       POSTFIX,
+	token.DECREMENT:       POSTFIX,
 }
 
 type (
@@ -76,23 +72,37 @@ type Parser struct {
 	l      *lexer.Lexer
 	errors []string
 
+	lexerErrors int // quantos erros do lexer já foram copiados para errors
+
+	tracer trace.Tracer
+
 	curToken  token.Token
 	peekToken token.Token
+	prevToken token.Token   // token anterior a curToken, usado por backup
+	pending   []token.Token // tokens devolvidos por backup, lidos antes do lexer
+
+	// noCompositeLit desliga literais compostos na condição de um 'if', onde 'x {' abre o bloco
+	// e não um literal 'Tipo { ... }'. Parênteses voltam a permiti-los, como em Go.
+	noCompositeLit bool
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
 
-func New(l *lexer.Lexer) *Parser {
-	initLogger()
-	p := &Parser{l: l, errors: []string{}}
+// New cria um Parser que consome os tokens de l. tracer pode ser nil, o que desliga o rastreamento.
+func New(l *lexer.Lexer, tracer trace.Tracer) *Parser {
+	p := &Parser{l: l, errors: []string{}, tracer: trace.OrNop(tracer)}
+	p.tracef("Iniciando nova sessão de parsing.")
 
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.CHAR, p.parseCharLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.BIT_NOT, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
@@ -112,8 +122,29 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.BIT_AND, p.parseInfixExpression)
+	p.registerInfix(token.BIT_OR, p.parseInfixExpression)
+	p.registerInfix(token.BIT_XOR, p.parseInfixExpression)
+	p.registerInfix(token.SHL, p.parseInfixExpression)
+	p.registerInfix(token.SHR, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.MODULO_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.AND_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.OR_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.XOR_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.SHL_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.SHR_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.INCREMENT, p.parsePostfixExpression)
+	p.registerInfix(token.DECREMENT, p.parsePostfixExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 	p.registerInfix(token.DOT, p.parseMemberExpression)
 
@@ -124,11 +155,20 @@ func New(l *lexer.Lexer) *Parser {
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
-	logger.Println(">> ParseProgram")
-	defer logger.Println("<< ParseProgram")
+	p.tracef(">> ParseProgram")
+	defer p.tracef("<< ParseProgram")
 
 	program := &ast.Program{Statements: []ast.Statement{}}
 	for !p.curTokenIs(token.EOF) {
+		if p.curTokenIs(token.SEMICOLON) {
+			p.nextToken()
+			continue
+		}
+		if p.curTokenIs(token.RBRACE) {
+			p.errorAt(p.curToken.Pos, "'}' inesperado fora de um bloco")
+			p.nextToken()
+			continue
+		}
 		stmt := p.parseStatement()
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
@@ -138,15 +178,8 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
-func (p *Parser) parseInterpolation() ast.Expression {
-	// TO-DO: implementar parsing de interpolação de string
-	// por enquanto, só retorna nil ou erro
-	p.errors = append(p.errors, "parseInterpolation não implementado")
-	return nil
-}
-
 func (p *Parser) parseTypeLiteral() ast.Expression {
 	// TO-DO: implementar parser de type literals
-	p.errors = append(p.errors, "parseTypeLiteral não implementado")
-	return nil
+	p.errorAt(p.curToken.Pos, "parseTypeLiteral não implementado")
+	return p.badExpr(p.curToken)
 }
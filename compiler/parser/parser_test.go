@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"taquion/compiler/lexer"
+)
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		errors []string
+		stmts  []string // String() de cada instrução do programa
+	}{
+		{
+			name: "erros independentes",
+			input: `let a = 1
+let = 2
+let b = )
+let c = 4
+func f() {
+    let d = 5 +* 6
+    let e = 7
+}
+let g = 8 9
+type T {
+    x: int
+    42
+    y: int
+}
+let h = 10
+`,
+			errors: []string{
+				`2:5: esperava o próximo token ser IDENT, mas obteve = ("=")`,
+				`3:9: nenhuma função de parsing de prefixo encontrada para ) (")")`,
+				`6:16: nenhuma função de parsing de prefixo encontrada para * ("*")`,
+				`9:11: esperava ';' ou quebra de linha após a instrução, mas obteve "9"`,
+				`12:5: token inesperado no corpo do tipo T: INT ("42")`,
+			},
+			stmts: []string{
+				"let a = 1;",
+				"<instrução inválida>",
+				"let b = <expressão inválida>;",
+				"let c = 4;",
+				"func f() let d = (5 + <expressão inválida>);let e = 7;",
+				"let g = 8;",
+				"<instrução inválida>",
+				"let h = 10;",
+			},
+		},
+		{
+			// No 'if', o ')' e o '{' esperados faltam no mesmo token: só o primeiro erro conta.
+			name: "tokens soltos",
+			input: `let b = )
+let c = 1
+}}} )))
+let x = 2
+if (x > { let y = 3 }
+let z = 4
+`,
+			errors: []string{
+				`1:9: nenhuma função de parsing de prefixo encontrada para ) (")")`,
+				`3:1: '}' inesperado fora de um bloco`,
+				`3:2: '}' inesperado fora de um bloco`,
+				`3:3: '}' inesperado fora de um bloco`,
+				`3:5: nenhuma função de parsing de prefixo encontrada para ) (")")`,
+				`5:9: nenhuma função de parsing de prefixo encontrada para { ("{")`,
+				`5:11: esperava o próximo token ser ), mas obteve LET ("let")`,
+				`5:21: '}' inesperado fora de um bloco`,
+			},
+			stmts: []string{
+				"let b = <expressão inválida>;",
+				"let c = 1;",
+				"<expressão inválida>",
+				"let x = 2;",
+				"<expressão inválida>",
+				"let y = 3;",
+				"let z = 4;",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(lexer.New(tt.input, nil), nil)
+			program := p.ParseProgram()
+
+			errs := p.Errors()
+			if strings.Join(errs, "\n") != strings.Join(tt.errors, "\n") {
+				t.Errorf("erros:\n%s\nesperava:\n%s", strings.Join(errs, "\n"), strings.Join(tt.errors, "\n"))
+			}
+
+			var stmts []string
+			for _, stmt := range program.Statements {
+				stmts = append(stmts, stmt.String())
+			}
+			if strings.Join(stmts, "\n") != strings.Join(tt.stmts, "\n") {
+				t.Errorf("instruções:\n%s\nesperava:\n%s", strings.Join(stmts, "\n"), strings.Join(tt.stmts, "\n"))
+			}
+		})
+	}
+}
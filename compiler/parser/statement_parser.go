@@ -7,73 +7,154 @@ import (
 )
 
 func (p *Parser) parseStatement() ast.Statement {
-	logger.Println("    >> parseStatement")
-	defer logger.Println("    << parseStatement")
+	p.tracef("    >> parseStatement")
+	defer p.tracef("    << parseStatement")
 
+	errorCount := len(p.errors)
+	var stmt ast.Statement
 	switch p.curToken.Type {
 	case token.CONST:
-		return p.parseConstStatement()
+		stmt = p.parseConstStatement()
 	case token.LET:
-		return p.parseLetStatement()
+		stmt = p.parseLetStatement()
 	case token.RETURN:
-		return p.parseReturnStatement()
+		stmt = p.parseReturnStatement()
 	case token.PACKAGE:
-		return p.parsePackageStatement()
+		stmt = p.parsePackageStatement()
+	case token.IMPORT:
+		stmt = p.parseImportStatement()
 	case token.FUNCTION:
-		return p.parseFunctionDeclaration()
+		stmt = p.parseFunctionDeclaration()
 	case token.TYPE:
-		return p.parseTypeDeclaration()
+		stmt = p.parseTypeDeclaration()
 	case token.WHILE:
-		return p.parseWhileStatement()
+		stmt = p.parseWhileStatement()
+	case token.FOR:
+		stmt = p.parseForStatement()
 	case token.BREAK:
-		return p.parseBreakStatement()
+		stmt = p.parseBreakStatement()
 	case token.CONTINUE:
-		return p.parseContinueStatement()
+		stmt = p.parseContinueStatement()
+	case token.MATCH:
+		stmt = p.parseMatchStatement()
 	default:
-		return p.parseExpressionStatement()
+		stmt = p.parseExpressionStatement()
 	}
+
+	if _, bad := stmt.(*ast.BadStmt); bad {
+		return stmt // badStmt já sincronizou o parser
+	}
+	if len(p.errors) == errorCount {
+		p.expectStatementEnd()
+	} else {
+		// A instrução teve erros internos: descarta o que sobrou dela sem repetir o diagnóstico.
+		p.synchronize()
+	}
+	return stmt
 }
 
-func (p *Parser) parseLetStatement() *ast.LetStatement {
+// expectStatementEnd consome o ';' que termina uma instrução, seja ele explícito ou inserido
+// pelo lexer numa quebra de linha. Antes de '}' ou do fim do arquivo ele é opcional.
+func (p *Parser) expectStatementEnd() {
+	switch {
+	case p.peekTokenIs(token.SEMICOLON):
+		p.nextToken()
+	case p.peekTokenIs(token.RBRACE), p.peekTokenIs(token.EOF):
+	default:
+		p.errorAt(p.peekToken.Pos, "esperava ';' ou quebra de linha após a instrução, mas obteve %q", p.peekToken.Literal)
+		p.synchronize()
+	}
+}
+
+// parseLetStatement analisa 'let x = e', 'let x: T = e', 'let x T = e' e 'let x: T' (valor zero).
+func (p *Parser) parseLetStatement() ast.Statement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 	if !p.expectPeek(token.IDENT) {
-		return nil
+		return p.badStmt(stmt.Token)
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	if !p.expectPeek(token.ASSIGN) {
-		return nil
+
+	// 'let q, r = f()' desestrutura os valores devolvidos por uma função.
+	if p.peekTokenIs(token.COMMA) {
+		stmt.Names = []*ast.Identifier{stmt.Name}
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return p.badStmt(stmt.Token)
+			}
+			stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		}
+		if !p.expectPeek(token.ASSIGN) {
+			return p.badStmt(stmt.Token)
+		}
+		p.nextToken()
+		stmt.Value = p.parseExpression(LOWEST)
+		return stmt
 	}
+
+	var ok bool
+	if stmt.Type, ok = p.parseTypeAnnotation(); !ok {
+		return p.badStmt(stmt.Token)
+	}
+	if !p.peekTokenIs(token.ASSIGN) {
+		if stmt.Type == nil {
+			p.errorAt(p.peekToken.Pos, "a variável %s precisa de um tipo ou de um valor inicial", stmt.Name.Value)
+			return p.badStmt(stmt.Token)
+		}
+		return stmt
+	}
+	p.nextToken()
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
-	}
 	return stmt
 }
 
-func (p *Parser) parseConstStatement() *ast.ConstStatement {
-	stmt := &ast.ConstStatement{Token: p.curToken}
+func (p *Parser) parseConstStatement() ast.Statement {
+	stmt := &ast.ConstStatement{Token: p.curToken, Doc: p.curToken.Doc}
 	if !p.expectPeek(token.IDENT) {
-		return nil
+		return p.badStmt(stmt.Token)
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	if !p.expectPeek(token.ASSIGN) {
-		return nil
+
+	var ok bool
+	if stmt.Type, ok = p.parseTypeAnnotation(); !ok {
+		return p.badStmt(stmt.Token)
+	}
+	if !p.peekTokenIs(token.ASSIGN) {
+		p.errorAt(p.peekToken.Pos, "a constante %s precisa de um valor inicial", stmt.Name.Value)
+		return p.badStmt(stmt.Token)
 	}
 	p.nextToken()
+	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
-	}
 	return stmt
 }
 
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
+	return p.parseReturn(true)
+}
+
+// parseReturn analisa um 'return'. multiple permite 'return q, r'; num braço de match a ','
+// separa os braços, e o 'return' devolve no máximo um valor.
+func (p *Parser) parseReturn(multiple bool) *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
+	// 'return' sem valor: seguido de ';' (ou quebra de linha), '}' ou fim do arquivo.
+	if p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.EOF) ||
+		(!multiple && p.peekTokenIs(token.COMMA)) {
+		return stmt
+	}
 	p.nextToken()
 	stmt.ReturnValue = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
+	// 'return q, r' devolve múltiplos valores.
+	if multiple && p.peekTokenIs(token.COMMA) {
+		tuple := &ast.TupleExpression{Token: p.curToken, Elements: []ast.Expression{stmt.ReturnValue}}
+		tuple.Token.Pos = stmt.ReturnValue.Pos()
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			p.nextToken()
+			tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+		}
+		stmt.ReturnValue = tuple
 	}
 	return stmt
 }
@@ -81,9 +162,6 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
-	}
 	return stmt
 }
 
@@ -108,136 +186,328 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		p.nextToken()
 	}
 
+	if p.curTokenIs(token.RBRACE) {
+		block.RBrace = p.curToken
+	}
 	return block
 }
 
-func (p *Parser) parsePackageStatement() *ast.PackageStatement {
+func (p *Parser) parsePackageStatement() ast.Statement {
 	stmt := &ast.PackageStatement{Token: p.curToken}
 	if !p.expectPeek(token.IDENT) {
-		return nil
+		return p.badStmt(stmt.Token)
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
+	return stmt
+}
+
+func (p *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+	if !p.expectPeek(token.STRING) {
+		return p.badStmt(stmt.Token)
+	}
+	stmt.Path = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+	if stmt.Path.Value == "" {
+		p.errorAt(p.curToken.Pos, "caminho de importação vazio")
 	}
 	return stmt
 }
 
-func (p *Parser) parseFunctionDeclaration() *ast.FunctionDeclaration {
-	decl := &ast.FunctionDeclaration{Token: p.curToken}
+func (p *Parser) parseFunctionDeclaration() ast.Statement {
+	decl := &ast.FunctionDeclaration{Token: p.curToken, Doc: p.curToken.Doc}
 	if !p.expectPeek(token.IDENT) {
-		return nil
+		return p.badStmt(decl.Token)
 	}
 	decl.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 	if !p.expectPeek(token.LPAREN) {
-		return nil
+		return p.badStmt(decl.Token)
 	}
 	decl.Parameters = p.parseFunctionParameters()
-	if !p.expectPeek(token.LBRACE) {
-		return nil
+	if decl.Parameters == nil {
+		return p.badStmt(decl.Token)
+	}
+	var ok bool
+	if decl.ReturnType, ok = p.parseReturnType(); !ok || !p.expectPeek(token.LBRACE) {
+		return p.badStmt(decl.Token)
 	}
 	decl.Body = p.parseBlockStatement()
 	return decl
 }
 
-func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+// parseWhileStatement analisa 'while cond { ... }'; os parênteses em volta da condição são opcionais.
+func (p *Parser) parseWhileStatement() ast.Statement {
 	stmt := &ast.WhileStatement{Token: p.curToken}
-	if !p.expectPeek(token.LPAREN) {
-		return nil
+	p.nextToken()
+	stmt.Condition = p.parseHeaderExpression()
+	if !p.expectPeek(token.LBRACE) {
+		return p.badStmt(stmt.Token)
 	}
+	stmt.Body = p.parseBlockStatement()
+	return stmt
+}
+
+// parseForStatement analisa as formas do 'for': 'for init; cond; post { }', 'for cond { }',
+// 'for { }', 'for v in arr { }' e 'for i, v in arr { }'.
+func (p *Parser) parseForStatement() ast.Statement {
+	forTok := p.curToken
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		return &ast.ForStatement{Token: forTok, Body: p.parseBlockStatement()}
+	}
+
 	p.nextToken()
-	stmt.Condition = p.parseExpression(LOWEST)
-	if !p.expectPeek(token.RPAREN) {
-		return nil
+	if p.curTokenIs(token.IDENT) && (p.peekTokenIs(token.IN) || p.peekTokenIs(token.COMMA)) {
+		return p.parseForInStatement(forTok)
+	}
+
+	stmt := &ast.ForStatement{Token: forTok}
+	if !p.curTokenIs(token.SEMICOLON) {
+		init := p.parseForClause()
+		if init == nil {
+			return p.badStmt(forTok)
+		}
+		// 'for cond { }': a única cláusula é a condição.
+		if p.peekTokenIs(token.LBRACE) {
+			exprStmt, ok := init.(*ast.ExpressionStatement)
+			if !ok {
+				p.errorAt(init.Pos(), "esperava uma condição no 'for', mas obteve %s", init.String())
+				return p.badStmt(forTok)
+			}
+			stmt.Condition = exprStmt.Expression
+			p.nextToken()
+			stmt.Body = p.parseBlockStatement()
+			return stmt
+		}
+		stmt.Init = init
+		if !p.expectPeek(token.SEMICOLON) {
+			return p.badStmt(forTok)
+		}
+	}
+
+	if !p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+		stmt.Condition = p.parseHeaderExpression()
+	}
+	if !p.expectPeek(token.SEMICOLON) {
+		return p.badStmt(forTok)
+	}
+
+	if !p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		if stmt.Post = p.parseForClause(); stmt.Post == nil {
+			return p.badStmt(forTok)
+		}
 	}
 	if !p.expectPeek(token.LBRACE) {
-		return nil
+		return p.badStmt(forTok)
 	}
 	stmt.Body = p.parseBlockStatement()
 	return stmt
 }
 
-func (p *Parser) parseBreakStatement() *ast.BreakStatement {
-	stmt := &ast.BreakStatement{Token: p.curToken}
-	if p.peekTokenIs(token.SEMICOLON) {
+// parseForClause analisa a inicialização ou o pós-comando de um 'for': um 'let' ou uma expressão.
+func (p *Parser) parseForClause() ast.Statement {
+	if p.curTokenIs(token.LET) {
+		saved := p.noCompositeLit
+		p.noCompositeLit = true
+		defer func() { p.noCompositeLit = saved }()
+		stmt := p.parseLetStatement()
+		if _, bad := stmt.(*ast.BadStmt); bad {
+			return nil
+		}
+		return stmt
+	}
+	return &ast.ExpressionStatement{Token: p.curToken, Expression: p.parseHeaderExpression()}
+}
+
+// parseForInStatement analisa 'for v in arr { }' e 'for i, v in arr { }' a partir da primeira variável.
+func (p *Parser) parseForInStatement(forTok token.Token) ast.Statement {
+	stmt := &ast.ForInStatement{Token: forTok}
+	stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if p.peekTokenIs(token.COMMA) {
 		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return p.badStmt(forTok)
+		}
+		stmt.Index = stmt.Value
+		stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+	if !p.expectPeek(token.IN) {
+		return p.badStmt(forTok)
+	}
+	p.nextToken()
+	stmt.Iterable = p.parseHeaderExpression()
+	if !p.expectPeek(token.LBRACE) {
+		return p.badStmt(forTok)
 	}
+	stmt.Body = p.parseBlockStatement()
 	return stmt
 }
 
-func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
-	stmt := &ast.ContinueStatement{Token: p.curToken}
-	if p.peekTokenIs(token.SEMICOLON) {
+// parseHeaderExpression analisa uma expressão no cabeçalho de um laço ou de um match,
+// onde 'x {' abre o corpo e não um literal composto.
+func (p *Parser) parseHeaderExpression() ast.Expression {
+	saved := p.noCompositeLit
+	p.noCompositeLit = true
+	defer func() { p.noCompositeLit = saved }()
+	return p.parseExpression(LOWEST)
+}
+
+// parseMatchStatement analisa 'match x { 1, 2 => ..., _ => ... }'. Os braços são separados
+// por ',' ou por quebra de linha.
+func (p *Parser) parseMatchStatement() ast.Statement {
+	stmt := &ast.MatchStatement{Token: p.curToken}
+	p.nextToken()
+	stmt.Subject = p.parseHeaderExpression()
+	if !p.expectPeek(token.LBRACE) {
+		return p.badStmt(stmt.Token)
+	}
+	p.nextToken()
+
+	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		if p.curTokenIs(token.COMMA) || p.curTokenIs(token.SEMICOLON) {
+			p.nextToken()
+			continue
+		}
+		arm := p.parseMatchArm()
+		if arm == nil {
+			// Descarta os braços restantes até o '}' do match, para não fechar o bloco de fora.
+			for depth := 0; !p.peekTokenIs(token.EOF); p.nextToken() {
+				if p.peekTokenIs(token.LBRACE) {
+					depth++
+				} else if p.peekTokenIs(token.RBRACE) {
+					if depth == 0 {
+						break
+					}
+					depth--
+				}
+			}
+			p.nextToken()
+			return &ast.BadStmt{From: stmt.Token, To: p.curToken}
+		}
+		stmt.Arms = append(stmt.Arms, arm)
 		p.nextToken()
 	}
+	if !p.curTokenIs(token.RBRACE) {
+		p.errorAt(p.curToken.Pos, "esperava '}' para fechar o match")
+		return p.badStmt(stmt.Token)
+	}
+	stmt.RBrace = p.curToken
 	return stmt
 }
 
-func (p *Parser) parseTypeDeclaration() *ast.TypeDeclaration {
+// parseMatchArm analisa um braço 'v1, v2 => corpo' ou '_ => corpo'. O corpo é um bloco ou
+// uma única instrução simples (expressão, return, break ou continue).
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{Token: p.curToken}
+	if p.curTokenIs(token.IDENT) && p.curToken.Literal == "_" && p.peekTokenIs(token.ARROW) {
+		arm.Default = true
+	} else {
+		for {
+			arm.Patterns = append(arm.Patterns, p.parseExpression(LOWEST))
+			if !p.peekTokenIs(token.COMMA) {
+				break
+			}
+			p.nextToken()
+			p.nextToken()
+		}
+	}
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+	p.nextToken()
+
+	if p.curTokenIs(token.LBRACE) {
+		arm.Body = p.parseBlockStatement()
+		return arm
+	}
+	var body ast.Statement
+	switch p.curToken.Type {
+	case token.RETURN:
+		body = p.parseReturn(false)
+	case token.BREAK:
+		body = p.parseBreakStatement()
+	case token.CONTINUE:
+		body = p.parseContinueStatement()
+	default:
+		body = &ast.ExpressionStatement{Token: p.curToken, Expression: p.parseExpression(LOWEST)}
+	}
+	arm.Body = &ast.BlockStatement{Token: arm.Token, Statements: []ast.Statement{body}}
+	return arm
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+	return stmt
+}
+
+func (p *Parser) parseTypeDeclaration() ast.Statement {
 	// Cria o nó da declaração de tipo. Token atual é 'type'.
-	stmt := &ast.TypeDeclaration{Token: p.curToken}
+	stmt := &ast.TypeDeclaration{Token: p.curToken, Doc: p.curToken.Doc}
 
 	// Espera o nome do tipo (ex: Pessoa)
 	if !p.expectPeek(token.IDENT) {
-		return nil
+		return p.badStmt(stmt.Token)
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
 	// Espera o abre chaves '{' que inicia o corpo do tipo
 	if !p.expectPeek(token.LBRACE) {
-		return nil
+		return p.badStmt(stmt.Token)
 	}
 
+	p.nextToken() // consome o '{'
+
 	stmt.Fields = []*ast.StructField{}
 	stmt.Methods = []*ast.FunctionLiteral{}
 
 	// Loop para analisar o corpo do tipo (campos e métodos)
 	// O loop continua enquanto não encontrarmos a chave de fechamento '}'
+	bad := false
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		// Separadores entre membros (explícitos ou inseridos em quebras de linha).
+		if p.curTokenIs(token.SEMICOLON) {
+			p.nextToken()
+			continue
+		}
 
+		var ok bool
+		switch {
 		// CASO 1: É um método
-		if p.curTokenIs(token.FUNCTION) {
-			method := &ast.FunctionLiteral{Token: p.curToken}
-
-			if !p.expectPeek(token.IDENT) { // Nome do método
-				return nil
-			}
-			method.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-
-			if !p.expectPeek(token.LPAREN) { // Parâmetros
-				return nil
-			}
-			method.Parameters = p.parseFunctionParameters()
-
-			// Suporte para tipo de retorno opcional (ex: func saudacao() string {...})
-			// Se não for uma chave, deve ser o tipo de retorno.
-			if !p.peekTokenIs(token.LBRACE) {
-				p.nextToken() // Consome o token do tipo (ex: 'string')
-				// Você pode querer criar um nó AST para o tipo de retorno aqui
-			}
-
-			if !p.expectPeek(token.LBRACE) { // Corpo do método
-				return nil
-			}
-			method.Body = p.parseBlockStatement() // Esta função termina com curToken em '}'
-			stmt.Methods = append(stmt.Methods, method)
+		case p.curTokenIs(token.FUNCTION):
+			ok = p.parseTypeMethod(stmt)
 
-			// CASO 2: É um campo
-		} else if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON) {
-			field := &ast.StructField{}
+		// CASO 2: É um campo
+		case p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON):
+			field := &ast.StructField{Doc: p.curToken.Doc}
 			field.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
 			p.nextToken() // Consome o nome do campo
 			p.nextToken() // Consome o ':'
 
-			field.Type = p.parseExpression(LOWEST) // Analisa a expressão do tipo
-			stmt.Fields = append(stmt.Fields, field)
+			if field.Type = p.parseType(); field.Type != nil {
+				stmt.Fields = append(stmt.Fields, field)
+				ok = true
+			}
 
-			// CASO 3: Token inesperado
-		} else {
-			// Pula tokens inesperados para evitar loop infinito
-			p.nextToken()
-			continue
+		// CASO 3: Token inesperado
+		case p.curTokenIs(token.IDENT):
+			p.peekError(token.COLON) // ex: 'b int' sem ':'
+		default:
+			p.errorAt(p.curToken.Pos, "token inesperado no corpo do tipo %s: %s (%q)",
+				stmt.Name.Value, p.curToken.Type, p.curToken.Literal)
+		}
+
+		// Um membro malformado é descartado até o fim da sua linha, e a análise continua no
+		// próximo membro para relatar os demais erros.
+		if !ok {
+			bad = true
+			p.skipMember()
 		}
 
 		// Após analisar um campo ou método, avança para o próximo token
@@ -247,9 +517,45 @@ func (p *Parser) parseTypeDeclaration() *ast.TypeDeclaration {
 
 	// Garante que a declaração de tipo foi fechada corretamente com '}'
 	if !p.curTokenIs(token.RBRACE) {
-		p.errors = append(p.errors, "esperava '}' para fechar a declaração de tipo")
-		return nil
+		p.errorAt(p.curToken.Pos, "esperava '}' para fechar a declaração de tipo %s", stmt.Name.Value)
+		return p.badStmt(stmt.Token)
 	}
+	if bad {
+		return &ast.BadStmt{From: stmt.Token, To: p.curToken}
+	}
+	stmt.RBrace = p.curToken
 
 	return stmt
 }
+
+// parseTypeMethod analisa um método no corpo de um tipo e o acrescenta a stmt. Devolve false
+// quando o método é malformado.
+func (p *Parser) parseTypeMethod(stmt *ast.TypeDeclaration) bool {
+	method := &ast.FunctionLiteral{Token: p.curToken, Doc: p.curToken.Doc}
+
+	if !p.expectPeek(token.IDENT) { // Nome do método
+		return false
+	}
+	method.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LPAREN) { // Parâmetros
+		return false
+	}
+	method.Parameters = p.parseFunctionParameters()
+	if method.Parameters == nil {
+		return false
+	}
+
+	// Tipo de retorno opcional (ex: func saudacao() string {...})
+	var ok bool
+	if method.ReturnType, ok = p.parseReturnType(); !ok {
+		return false
+	}
+
+	if !p.expectPeek(token.LBRACE) { // Corpo do método
+		return false
+	}
+	method.Body = p.parseBlockStatement() // Esta função termina com curToken em '}'
+	stmt.Methods = append(stmt.Methods, method)
+	return true
+}
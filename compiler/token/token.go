@@ -1,16 +1,43 @@
 package token
 
-import (
-	"log"
-	"os"
-	"sync"
-)
+import "fmt"
 
 type TokenType string
 
+// Position identifica um ponto no código fonte.
+// Line e Column começam em 1; Offset é o deslocamento em bytes a partir do início do arquivo.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+	Offset int
+}
+
+// IsValid informa se a posição foi preenchida pelo lexer.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String formata a posição como "arquivo:linha:coluna" (ou "linha:coluna" sem arquivo).
+func (p Position) String() string {
+	if !p.IsValid() {
+		if p.File != "" {
+			return p.File
+		}
+		return "-"
+	}
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position // início do token
+	End     Position // posição imediatamente após o último caractere do token
+	Doc     string   // doc comment ('///') imediatamente anterior ao token, sem os marcadores
 }
 
 const (
@@ -21,7 +48,9 @@ const (
 	// Identificadores + literais
 	IDENT  = "IDENT"
 	INT    = "INT"
+	FLOAT  = "FLOAT"
 	STRING = "STRING"
+	CHAR   = "CHAR"
 
 	// Operadores
 	ASSIGN   = "="
@@ -33,8 +62,34 @@ const (
 	MODULO   = "%"
 	LT       = "<"
 	GT       = ">"
+	LT_EQ    = "<="
+	GT_EQ    = ">="
 	EQ       = "=="
 	NOT_EQ   = "!="
+	AND      = "&&"
+	OR       = "||"
+
+	// Operadores bit a bit
+	BIT_AND = "&"
+	BIT_OR  = "|"
+	BIT_XOR = "^"
+	BIT_NOT = "~"
+	SHL     = "<<"
+	SHR     = ">>"
+
+	// Atribuição composta, incremento e decremento
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	MODULO_ASSIGN   = "%="
+	AND_ASSIGN      = "&="
+	OR_ASSIGN       = "|="
+	XOR_ASSIGN      = "^="
+	SHL_ASSIGN      = "<<="
+	SHR_ASSIGN      = ">>="
+	INCREMENT       = "++"
+	DECREMENT       = "--"
 
 	// Delimitadores
 	COMMA     = ","
@@ -47,14 +102,17 @@ const (
 	RBRACE    = "}"
 	LBRACKET  = "["
 	RBRACKET  = "]"
+	ARROW     = "=>"
 
-	// Interpolação de strings
+	// Interpolação de strings: "a${x}b${y}c" vira INTERP_START("a") x INTERP_MID("b") y INTERP_END("c")
 	DOLLAR       = "$"
-	INTERP_START = "${"
-	INTERP_END   = "}"
+	INTERP_START = "INTERP_START" // trecho antes do primeiro "${"
+	INTERP_MID   = "INTERP_MID"   // trecho entre um "}" e o próximo "${"
+	INTERP_END   = "INTERP_END"   // trecho entre o último "}" e a aspa de fechamento
 
 	// Palavras-chave
 	PACKAGE  = "PACKAGE"
+	IMPORT   = "IMPORT"
 	FUNCTION = "FUNCTION"
 	CONST    = "CONST"
 	LET      = "LET"
@@ -64,6 +122,9 @@ const (
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
 	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	MATCH    = "MATCH"
 	BREAK    = "BREAK"
 	CONTINUE = "CONTINUE"
 	TYPE     = "TYPE"
@@ -71,6 +132,7 @@ const (
 
 var keywords = map[string]TokenType{
 	"package":  PACKAGE,
+	"import":   IMPORT,
 	"func":     FUNCTION,
 	"const":    CONST,
 	"return":   RETURN,
@@ -80,48 +142,21 @@ var keywords = map[string]TokenType{
 	"false":    FALSE,
 	"let":      LET,
 	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"match":    MATCH,
 	"break":    BREAK,
 	"continue": CONTINUE,
 	"type":     TYPE,
 }
 
-var (
-	logger   *log.Logger
-	logFile  *os.File
-	initOnce sync.Once
-)
-
-func initLogger() {
-	initOnce.Do(func() {
-		var err error
-		logFile, err = os.OpenFile("log/token.log", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
-		if err != nil {
-			log.Fatalf("Erro ao abrir arquivo de log token.log: %v", err)
-		}
-		logger = log.New(logFile, "TOKEN: ", log.LstdFlags)
-		logger.Println("=== Nova sessão de log do token iniciada ===")
-	})
-}
-
-func CloseLogger() {
-	if logFile != nil {
-		logger.Println("=== Encerrando sessão de log do token ===")
-		logFile.Close()
-	}
-}
-
 func NewToken(t TokenType, lit string) Token {
-	initLogger()
-	logger.Printf("Criando token - Tipo: %-10s | Literal: %q\n", t, lit)
 	return Token{Type: t, Literal: lit}
 }
 
 func LookupIdent(ident string) TokenType {
-	initLogger()
 	if tok, ok := keywords[ident]; ok {
-		logger.Printf("LookupIdent: %q é palavra-chave -> TokenType: %q\n", ident, tok)
 		return tok
 	}
-	logger.Printf("LookupIdent: %q é IDENT padrão\n", ident)
 	return IDENT
 }
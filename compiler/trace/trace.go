@@ -0,0 +1,103 @@
+// Package trace implementa o rastreamento opcional das fases do compilador.
+// Ele fica desligado por padrão: lexer, parser e codegen recebem um Tracer e, sem um, usam Nop.
+package trace
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Phase identifica uma fase do compilador que pode ser rastreada.
+type Phase string
+
+const (
+	Lexer   Phase = "lexer"
+	Parser  Phase = "parser"
+	Codegen Phase = "codegen"
+)
+
+// Phases lista todas as fases rastreáveis, na ordem do pipeline.
+var Phases = []Phase{Lexer, Parser, Codegen}
+
+// Tracer recebe as mensagens de rastreamento das fases do compilador.
+type Tracer interface {
+	// Enabled informa se a fase está sendo rastreada, para evitar montar mensagens à toa.
+	Enabled(phase Phase) bool
+	Tracef(phase Phase, format string, args ...interface{})
+}
+
+// Nop descarta todas as mensagens.
+var Nop Tracer = nop{}
+
+type nop struct{}
+
+func (nop) Enabled(Phase) bool                   { return false }
+func (nop) Tracef(Phase, string, ...interface{}) {}
+
+// OrNop devolve t, ou Nop quando t é nil.
+func OrNop(t Tracer) Tracer {
+	if t == nil {
+		return Nop
+	}
+	return t
+}
+
+// writerTracer escreve uma linha por mensagem em w, prefixada pelo nome da fase.
+type writerTracer struct {
+	mu     sync.Mutex
+	w      io.Writer
+	phases map[Phase]bool
+}
+
+// New cria um Tracer que escreve em w as mensagens das fases indicadas.
+func New(w io.Writer, phases ...Phase) Tracer {
+	t := &writerTracer{w: w, phases: make(map[Phase]bool)}
+	for _, phase := range phases {
+		t.phases[phase] = true
+	}
+	return t
+}
+
+func (t *writerTracer) Enabled(phase Phase) bool {
+	return t.phases[phase]
+}
+
+func (t *writerTracer) Tracef(phase Phase, format string, args ...interface{}) {
+	if !t.phases[phase] {
+		return
+	}
+	msg := strings.TrimRight(fmt.Sprintf(format, args...), "\n")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintf(t.w, "%-8s %s\n", strings.ToUpper(string(phase))+":", msg)
+}
+
+// ParsePhases interpreta a lista de fases do flag --trace, como "lexer,parser" ou "all".
+func ParsePhases(list string) ([]Phase, error) {
+	var phases []Phase
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+			continue
+		case name == "all":
+			phases = append(phases, Phases...)
+		case isPhase(Phase(name)):
+			phases = append(phases, Phase(name))
+		default:
+			return nil, fmt.Errorf("fase de trace desconhecida: %q (use lexer, parser, codegen ou all)", name)
+		}
+	}
+	return phases, nil
+}
+
+func isPhase(p Phase) bool {
+	for _, known := range Phases {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
@@ -6,11 +6,8 @@ from pathlib import Path
 # Caminhos
 BASE_DIR     = Path(__file__).parent.parent.parent       # …/taquion
 COMPILER_DIR = BASE_DIR / "compiler"                     # …/taquion/compiler
-LOG_DIR      = COMPILER_DIR / "log"
-LEXER_LOG    = LOG_DIR / "lexer.log"
-PARSER_LOG   = LOG_DIR / "parser.log"
-CODEGEN_LOG  = LOG_DIR / "codegen.log"
 BUILD_DIR    = BASE_DIR / "build"
+TRACE_FILE   = BUILD_DIR / "trace.log"
 TAQC_BIN     = BUILD_DIR / "taquionc.exe"
 
 def run_example(example: Path) -> tuple[int, str, float]:
@@ -21,13 +18,11 @@ def run_example(example: Path) -> tuple[int, str, float]:
       - O binário final (.exe) é salvo NA MESMA PASTA do exemplo,
         com o nome da PASTA: <nome_da_pasta>.exe
     """
-    # Limpa logs antigos
-    if LOG_DIR.exists():
-        for f in (LEXER_LOG, PARSER_LOG, CODEGEN_LOG):
-            try:
-                f.unlink()
-            except FileNotFoundError:
-                pass
+    # Limpa o trace antigo
+    try:
+        TRACE_FILE.unlink()
+    except FileNotFoundError:
+        pass
 
     # Saídas intermediárias continuam na build/
     ir_file   = BUILD_DIR / "output.ll"
@@ -51,7 +46,8 @@ def run_example(example: Path) -> tuple[int, str, float]:
 
     # 1) Gerar LLVM IR
     p1 = subprocess.run(
-        [str(TAQC_BIN), str(example), "-o", str(ir_file)],
+        [str(TAQC_BIN), str(example), "-o", str(ir_file),
+         "--trace=lexer,parser", f"--trace-out={TRACE_FILE}"],
         cwd=COMPILER_DIR, capture_output=True, text=True
     )
     if p1.returncode != 0:
@@ -72,12 +68,11 @@ def run_example(example: Path) -> tuple[int, str, float]:
     )
     duration = time.time() - start
 
-    # 4) Lê logs gerados
+    # 4) Lê o trace do lexer e do parser
     log_content = ""
-    for log_file in (LEXER_LOG, PARSER_LOG):
-        if log_file.exists():
-            log_content += f"\n=== {log_file.name} ===\n"
-            log_content += log_file.read_text()
+    if TRACE_FILE.exists():
+        log_content += f"\n=== {TRACE_FILE.name} ===\n"
+        log_content += TRACE_FILE.read_text()
 
     output = (p3.stdout or p3.stderr) + log_content
     return p3.returncode, output, duration